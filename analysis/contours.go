@@ -0,0 +1,106 @@
+// Package analysis derives vector features, such as coastlines and contour
+// lines, from a generated world.
+package analysis
+
+import (
+	"perlin_noise/geom"
+	"perlin_noise/world"
+)
+
+// simplifyTolerance is how far in pixels a simplified contour may stray from
+// the traced one.
+const simplifyTolerance = 0.75
+
+// edge identifies the lattice edge from pixel (x, y) to its right neighbor
+// (horizontal) or to the one below it (vertical).
+type edge struct {
+	x, y     int
+	vertical bool
+}
+
+// ExtractContours traces every closed contour of w's elevation at level with
+// marching squares and simplifies each with Douglas–Peucker. Use the world's
+// SeaLevel to get the coastlines. The map is treated as lying below level
+// beyond its borders, so all rings are closed. Rings around areas at or above
+// level run clockwise on screen, so their Area is positive; rings around the
+// holes within them, such as lakes, run counterclockwise.
+func ExtractContours(w *world.World, level float64) []geom.Polygon {
+	width, height := w.Params.Width, w.Params.Height
+	value := func(x, y int) float64 {
+		if x < 0 || y < 0 || x >= width || y >= height {
+			return level - 1
+		}
+		return w.ElevationAt(x, y)
+	}
+
+	// crossing returns where the contour crosses e, interpolated between its ends
+	crossing := func(e edge) geom.Point {
+		x1, y1 := e.x+1, e.y
+		if e.vertical {
+			x1, y1 = e.x, e.y+1
+		}
+		a, b := value(e.x, e.y), value(x1, y1)
+		t := (level - a) / (b - a)
+		return geom.Point{
+			X: float64(e.x) + t*float64(x1-e.x),
+			Y: float64(e.y) + t*float64(y1-e.y),
+		}
+	}
+
+	// next links each crossing to the following one along its contour; the
+	// area at or above level is always on the same side of a segment
+	next := make(map[edge]edge)
+	var starts []edge
+	for y := -1; y < height; y++ {
+		for x := -1; x < width; x++ {
+			// corners and the edges leaving them, clockwise from the top left
+			corners := [4]float64{value(x, y), value(x+1, y), value(x+1, y+1), value(x, y+1)}
+			edges := [4]edge{{x, y, false}, {x + 1, y, true}, {x, y + 1, false}, {x, y, true}}
+
+			// crossings in clockwise order; entering and exiting ones alternate
+			var cross []edge
+			var entering []bool
+			for i := range corners {
+				in, nextIn := corners[i] >= level, corners[(i+1)%4] >= level
+				if in != nextIn {
+					cross = append(cross, edges[i])
+					entering = append(entering, nextIn)
+				}
+			}
+
+			// the high corners lie clockwise between an entering crossing and
+			// the following exit; in a saddle cell, pairing each entry with the
+			// preceding exit instead cuts off the low corners, joining the high ones
+			step := 1
+			if len(cross) == 4 && (corners[0]+corners[1]+corners[2]+corners[3])/4 >= level {
+				step = len(cross) - 1
+			}
+			for k, e := range cross {
+				if entering[k] {
+					// traced from the exit back to the entry, so that rings
+					// around high areas run clockwise
+					exit := cross[(k+step)%len(cross)]
+					next[exit] = e
+					starts = append(starts, exit)
+				}
+			}
+		}
+	}
+
+	var polygons []geom.Polygon
+	visited := make(map[edge]bool, len(next))
+	for _, start := range starts {
+		if visited[start] {
+			continue
+		}
+		var ring geom.Polygon
+		for e := start; !visited[e]; e = next[e] {
+			visited[e] = true
+			ring = append(ring, crossing(e))
+		}
+		if ring = ring.Simplify(simplifyTolerance); len(ring) >= 3 {
+			polygons = append(polygons, ring)
+		}
+	}
+	return polygons
+}
@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"perlin_noise/geom"
+	"perlin_noise/heightfield"
+	"perlin_noise/world"
+)
+
+// gridWorld returns a width x height world at sea level 0.5 whose cells are
+// 0.8 where high reports true and 0.2 elsewhere.
+func gridWorld(width, height int, high func(x, y int) bool) *world.World {
+	f := heightfield.New(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if high(x, y) {
+				f.Set(x, y, 0.8)
+			} else {
+				f.Set(x, y, 0.2)
+			}
+		}
+	}
+	return &world.World{Params: world.Params{Width: width, Height: height, SeaLevel: 0.5}, Elevation: f}
+}
+
+// TestExtractContoursIslandLake traces an island with a lake in it: one ring
+// of positive area around the island and one of negative area around the
+// lake, so that land lies inside the first and outside the second.
+func TestExtractContoursIslandLake(t *testing.T) {
+	island, lake := geom.Point{X: 14, Y: 20}, geom.Point{X: 26, Y: 20}
+	// pixels of the island and of the lake in it
+	islandCells, lakeCells := 0, 0
+	w := gridWorld(40, 40, func(x, y int) bool {
+		p := geom.Point{X: float64(x), Y: float64(y)}
+		if p.Dist(geom.Point{X: 20, Y: 20}) > 14 {
+			return false
+		}
+		islandCells++
+		if p.Dist(lake) <= 4 {
+			lakeCells++
+			return false
+		}
+		return true
+	})
+	rings := ExtractContours(w, 0.5)
+	if len(rings) != 2 {
+		t.Fatalf("%d rings, want 2", len(rings))
+	}
+	var coast, shore geom.Polygon
+	for _, r := range rings {
+		if r.Area() > 0 {
+			coast = r
+		} else {
+			shore = r
+		}
+	}
+	if coast == nil || shore == nil {
+		t.Fatalf("ring areas %v and %v, want one positive and one negative", rings[0].Area(), rings[1].Area())
+	}
+	// each ring runs halfway between pixels, cutting their outer corners
+	if a := coast.Area(); math.Abs(a-float64(islandCells)) > 0.1*a {
+		t.Errorf("island area %v, want about %d", a, islandCells)
+	}
+	if a := -shore.Area(); math.Abs(a-float64(lakeCells)) > 0.25*a {
+		t.Errorf("lake area %v, want about %d", a, lakeCells)
+	}
+
+	land := func(p geom.Point) bool { return coast.Contains(p) && !shore.Contains(p) }
+	if !land(island) {
+		t.Error("island center is not land")
+	}
+	if land(lake) {
+		t.Error("lake center is land")
+	}
+	if land(geom.Point{X: 2, Y: 2}) {
+		t.Error("open sea is land")
+	}
+}
+
+// TestExtractContoursBorder checks that land running off the map is closed
+// along the border into a single ring of positive area.
+func TestExtractContoursBorder(t *testing.T) {
+	w := gridWorld(10, 10, func(x, y int) bool { return x < 5 })
+	rings := ExtractContours(w, 0.5)
+	if len(rings) != 1 {
+		t.Fatalf("%d rings, want 1", len(rings))
+	}
+	// beyond the border the map counts as 1 below the level, so the ring
+	// runs 0.3/1.3 of a cell outside it, and halfway to the sea inside
+	out := 0.3 / 1.3
+	if a, want := rings[0].Area(), (4.5+out)*(9+2*out); math.Abs(a-want) > 3 {
+		t.Errorf("area %v, want about %v", a, want)
+	}
+	if !rings[0].Contains(geom.Point{X: 0, Y: 0}) || rings[0].Contains(geom.Point{X: 9, Y: 9}) {
+		t.Error("ring does not enclose the land half")
+	}
+}
+
+// TestExtractContoursSaddle checks the cell where two diagonal blocks of
+// high pixels meet at a corner: it joins them into one ring when its mean is
+// at or above the level, and leaves two rings otherwise.
+func TestExtractContoursSaddle(t *testing.T) {
+	tests := []struct {
+		low   float64
+		rings int
+	}{
+		{0.1, 2}, // mean 0.45, below the level
+		{0.3, 1}, // mean 0.55
+	}
+	for _, tt := range tests {
+		l := tt.low
+		f := heightfield.New(6, 6)
+		f.Data = []float64{
+			0, 0, 0, 0, 0, 0,
+			0, 0.8, 0.8, 0, 0, 0,
+			0, 0.8, 0.8, l, 0, 0,
+			0, 0, l, 0.8, 0.8, 0,
+			0, 0, 0, 0.8, 0.8, 0,
+			0, 0, 0, 0, 0, 0,
+		}
+		w := &world.World{Params: world.Params{Width: 6, Height: 6}, Elevation: f}
+		rings := ExtractContours(w, 0.5)
+		if len(rings) != tt.rings {
+			t.Errorf("saddle low %v: %d rings, want %d", tt.low, len(rings), tt.rings)
+		}
+		for _, r := range rings {
+			if r.Area() <= 0 {
+				t.Errorf("saddle low %v: ring area %v, want positive", tt.low, r.Area())
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"perlin_noise/world"
+)
+
+// Landmasses returns the area in cells of every landmass of w, largest first.
+// Land cells belong to the same landmass when they touch along an edge.
+func Landmasses(w *world.World) []int {
+	width, height := w.Params.Width, w.Params.Height
+	seen := make([]bool, width*height)
+	var areas []int
+	var stack []int
+	for start := range seen {
+		if seen[start] || w.ElevationAt(start%width, start/width) < w.Params.SeaLevel {
+			continue
+		}
+		seen[start] = true
+		stack = append(stack[:0], start)
+		area := 0
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			area++
+			x, y := i%width, i/width
+			for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+				nx, ny := x+d[0], y+d[1]
+				if nx < 0 || ny < 0 || nx >= width || ny >= height {
+					continue
+				}
+				j := ny*width + nx
+				if !seen[j] && w.ElevationAt(nx, ny) >= w.Params.SeaLevel {
+					seen[j] = true
+					stack = append(stack, j)
+				}
+			}
+		}
+		areas = append(areas, area)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(areas)))
+	return areas
+}
+
+// CreationLog describes the notable facts of w in a few sentences, one per
+// line, with areas converted at kmPerPixel. The same world always gives the
+// same log, so it can be kept as a seed for its lore.
+func CreationLog(w *world.World, kmPerPixel float64) []string {
+	width, height := w.Params.Width, w.Params.Height
+	land := w.LandCells()
+	log := []string{fmt.Sprintf("Seed %d: a %d×%d px world, %.0f%% of it land.",
+		w.Params.Seed, width, height, 100*float64(land)/float64(width*height))}
+
+	switch areas := Landmasses(w); len(areas) {
+	case 0:
+		log = append(log, "No land rose from the sea.")
+	case 1:
+		log = append(log, fmt.Sprintf("A single landmass rose from the sea, covering %d px² (%.0f km²).",
+			areas[0], float64(areas[0])*kmPerPixel*kmPerPixel))
+	default:
+		log = append(log, fmt.Sprintf("%d landmasses rose from the sea; the largest covers %d px² (%.0f km²).",
+			len(areas), areas[0], float64(areas[0])*kmPerPixel*kmPerPixel))
+	}
+
+	// scan in row order so ties always go to the same cell
+	peakX, peakY, deepX, deepY := 0, 0, 0, 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if w.ElevationAt(x, y) > w.ElevationAt(peakX, peakY) {
+				peakX, peakY = x, y
+			}
+			if w.ElevationAt(x, y) < w.ElevationAt(deepX, deepY) {
+				deepX, deepY = x, y
+			}
+		}
+	}
+	if land > 0 {
+		log = append(log, fmt.Sprintf("The highest peak stands at (%d, %d), elevation %.3f.",
+			peakX, peakY, w.ElevationAt(peakX, peakY)))
+	}
+	if land < width*height {
+		log = append(log, fmt.Sprintf("The deepest water lies at (%d, %d), elevation %.3f.",
+			deepX, deepY, w.ElevationAt(deepX, deepY)))
+	}
+
+	switch ranges := MountainRanges(w, MinRangeCells); len(ranges) {
+	case 0:
+	case 1:
+		log = append(log, fmt.Sprintf("One mountain range rose, the %s, crowned by %s at elevation %.3f.",
+			ranges[0].Name, ranges[0].PeakName, ranges[0].PeakElevation))
+	default:
+		log = append(log, fmt.Sprintf("%d mountain ranges rose; the greatest, the %s, is crowned by %s at elevation %.3f.",
+			len(ranges), ranges[0].Name, ranges[0].PeakName, ranges[0].PeakElevation))
+	}
+
+	switch len(w.POIs) {
+	case 0:
+		log = append(log, "No points of interest were founded.")
+	case 1:
+		log = append(log, "One point of interest was founded.")
+	default:
+		log = append(log, fmt.Sprintf("%d points of interest were founded.", len(w.POIs)))
+	}
+	return log
+}
@@ -0,0 +1,125 @@
+package analysis
+
+import (
+	"math"
+
+	"perlin_noise/world"
+)
+
+// Weights of the habitability factors; they sum to 1.
+const (
+	flatnessWeight = 0.4
+	waterWeight    = 0.4
+	climateWeight  = 0.2
+)
+
+// waterRange is the distance in pixels at which water access has fallen to
+// 1/e of its value on the coast.
+const waterRange = 20.0
+
+// Ruggedness returns the Terrain Ruggedness Index (Riley et al.) of every
+// cell of w, row-major: the root of the summed squared height differences to
+// its eight neighbors. Flat ground scores 0; cells on the map edge compare
+// with the neighbors they have.
+func Ruggedness(w *world.World) []float64 {
+	width, height := w.Params.Width, w.Params.Height
+	tri := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			h := w.ElevationAt(x, y)
+			sum := 0.0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= width || ny >= height {
+						continue
+					}
+					d := w.ElevationAt(nx, ny) - h
+					sum += d * d
+				}
+			}
+			tri[y*width+x] = math.Sqrt(sum)
+		}
+	}
+	return tri
+}
+
+// Habitability scores every land cell of w in [0,1], row-major, from how
+// flat it is (by the ruggedness tri, relative to the most rugged cell), how
+// close it is to water, and a climate proxy: with no climate model, lower
+// land counts as milder. Sea cells score 0.
+func Habitability(w *world.World, tri []float64) []float64 {
+	width, height := w.Params.Width, w.Params.Height
+	sea := w.Params.SeaLevel
+
+	maxTRI := 0.0
+	for _, v := range tri {
+		maxTRI = max(maxTRI, v)
+	}
+	dist := seaDistance(w)
+
+	score := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			h := w.ElevationAt(x, y)
+			if h < sea {
+				continue
+			}
+			flatness := 1.0
+			if maxTRI > 0 {
+				flatness = 1 - tri[i]/maxTRI
+			}
+			water := math.Exp(-dist[i] / waterRange)
+			climate := 1.0
+			if sea < 1 {
+				climate = 1 - (h-sea)/(1-sea)
+			}
+			score[i] = flatnessWeight*flatness + waterWeight*water + climateWeight*climate
+		}
+	}
+	return score
+}
+
+// seaDistance returns the approximate distance in pixels from every cell of
+// w to the nearest sea cell (0 at sea), with a two-pass 3-4 chamfer transform.
+// A world without sea has every distance at +Inf.
+func seaDistance(w *world.World) []float64 {
+	width, height := w.Params.Width, w.Params.Height
+	dist := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if w.ElevationAt(x, y) >= w.Params.SeaLevel {
+				dist[y*width+x] = math.Inf(1)
+			}
+		}
+	}
+
+	relax := func(x, y, dx, dy int, cost float64) {
+		nx, ny := x+dx, y+dy
+		if nx < 0 || ny < 0 || nx >= width || ny >= height {
+			return
+		}
+		i := y*width + x
+		dist[i] = min(dist[i], dist[ny*width+nx]+cost)
+	}
+	// orthogonal steps cost 1 and diagonal ones 4/3, the 3-4 chamfer weights
+	const diag = 4.0 / 3.0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			relax(x, y, -1, 0, 1)
+			relax(x, y, 0, -1, 1)
+			relax(x, y, -1, -1, diag)
+			relax(x, y, 1, -1, diag)
+		}
+	}
+	for y := height - 1; y >= 0; y-- {
+		for x := width - 1; x >= 0; x-- {
+			relax(x, y, 1, 0, 1)
+			relax(x, y, 0, 1, 1)
+			relax(x, y, 1, 1, diag)
+			relax(x, y, -1, 1, diag)
+		}
+	}
+	return dist
+}
@@ -0,0 +1,141 @@
+package analysis
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+
+	"perlin_noise/world"
+)
+
+// MountainRise is how far above sea level a cell must rise to count as
+// mountain, where the map turns to mountain colors.
+const MountainRise = 0.20
+
+// MinRangeCells is the smallest group of mountain cells MountainRanges
+// reports; smaller ones are lone hills rather than ranges.
+const MinRangeCells = 40
+
+// MountainRange is a connected group of mountain cells with a generated name
+// and its highest peak.
+type MountainRange struct {
+	Name  string
+	Cells int
+	// LabelX, LabelY is the mountain cell nearest the centroid of the range,
+	// where its name goes on the map.
+	LabelX, LabelY int
+
+	PeakName      string
+	PeakX, PeakY  int
+	PeakElevation float64
+}
+
+// MountainRanges groups the mountain cells of w (MountainRise or more above
+// sea level) that touch along an edge or a corner, and returns the groups of
+// at least minCells cells, largest first. Names come from the world's seed,
+// so the same world always gets the same names.
+func MountainRanges(w *world.World, minCells int) []MountainRange {
+	width, height := w.Params.Width, w.Params.Height
+	level := w.Params.SeaLevel + MountainRise
+	seen := make([]bool, width*height)
+	var ranges []MountainRange
+	var stack, cells []int
+	for start := range seen {
+		if seen[start] || w.ElevationAt(start%width, start/width) < level {
+			continue
+		}
+		seen[start] = true
+		stack = append(stack[:0], start)
+		cells = cells[:0]
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			cells = append(cells, i)
+			x, y := i%width, i/width
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= width || ny >= height {
+						continue
+					}
+					j := ny*width + nx
+					if !seen[j] && w.ElevationAt(nx, ny) >= level {
+						seen[j] = true
+						stack = append(stack, j)
+					}
+				}
+			}
+		}
+		if len(cells) < minCells {
+			continue
+		}
+		ranges = append(ranges, newMountainRange(w, cells))
+	}
+
+	// cells are found in row order, so equal areas keep a fixed order
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].Cells > ranges[j].Cells })
+	r := rand.New(rand.NewSource(w.Params.Seed))
+	used := map[string]bool{}
+	for i := range ranges {
+		ranges[i].Name = uniqueName(r, used) + " " + rangeKinds[r.Intn(len(rangeKinds))]
+		ranges[i].PeakName = "Mount " + uniqueName(r, used)
+	}
+	return ranges
+}
+
+// newMountainRange measures the range made of cells, leaving it unnamed.
+func newMountainRange(w *world.World, cells []int) MountainRange {
+	width := w.Params.Width
+	mr := MountainRange{Cells: len(cells)}
+	sumX, sumY := 0, 0
+	peak := cells[0]
+	for _, i := range cells {
+		sumX += i % width
+		sumY += i / width
+		if w.ElevationAt(i%width, i/width) > w.ElevationAt(peak%width, peak/width) {
+			peak = i
+		}
+	}
+	mr.PeakX, mr.PeakY = peak%width, peak/width
+	mr.PeakElevation = w.ElevationAt(mr.PeakX, mr.PeakY)
+
+	// a curved range can have its centroid outside it
+	cx, cy := float64(sumX)/float64(len(cells)), float64(sumY)/float64(len(cells))
+	best := -1.0
+	for _, i := range cells {
+		dx, dy := float64(i%width)-cx, float64(i/width)-cy
+		if d := dx*dx + dy*dy; best < 0 || d < best {
+			best = d
+			mr.LabelX, mr.LabelY = i%width, i/width
+		}
+	}
+	return mr
+}
+
+// rangeKinds are the words a range name ends in.
+var rangeKinds = []string{"Mountains", "Range", "Peaks", "Heights", "Spine"}
+
+// Names are built from syllables of an onset, a vowel and an optional coda.
+var (
+	nameOnsets = []string{"b", "d", "g", "k", "m", "n", "r", "s", "t", "v", "th", "dr", "kr", "st", "gr", "z"}
+	nameVowels = []string{"a", "e", "i", "o", "u", "ai", "au", "ei"}
+	nameCodas  = []string{"", "", "n", "r", "l", "th", "k", "m", "s"}
+)
+
+// uniqueName returns a capitalized name of two or three syllables drawn from
+// r that is not in used yet, and adds it to used.
+func uniqueName(r *rand.Rand, used map[string]bool) string {
+	for {
+		var b strings.Builder
+		for n := 2 + r.Intn(2); n > 0; n-- {
+			b.WriteString(nameOnsets[r.Intn(len(nameOnsets))])
+			b.WriteString(nameVowels[r.Intn(len(nameVowels))])
+			b.WriteString(nameCodas[r.Intn(len(nameCodas))])
+		}
+		name := strings.ToUpper(b.String()[:1]) + b.String()[1:]
+		if !used[name] {
+			used[name] = true
+			return name
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Command goldens renders a fixed set of small scenes and compares each with
+// its reference image, so changes to the renderers show up as image diffs.
+//
+// Run it from the repository root:
+//
+//	go run ./cmd/goldens            # compare; writes <scene>.diff.png on failure
+//	go run ./cmd/goldens -update    # accept the current output as the new goldens
+//	go run ./cmd/goldens -workers 1 # generate on one goroutine; the output must not change
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"perlin_noise/imagediff"
+	"perlin_noise/render"
+	"perlin_noise/world"
+)
+
+// scene is one golden image: a world and how to draw it.
+type scene struct {
+	name   string
+	params func(p *world.Params)
+	opts   render.Options
+}
+
+// sceneSize keeps the goldens small; every scene is square.
+const sceneSize = 160
+
+var scenes = []scene{
+	{name: "default"},
+	{name: "lowsea", params: func(p *world.Params) { p.SeaLevel = 0.35 }},
+	{name: "detail_ao", params: func(p *world.Params) { p.SeaLevel = 0.35 }, opts: render.Options{DetailIntensity: 0.8, AOStrength: 0.6}},
+	{name: "water", params: func(p *world.Params) { p.SeaLevel = 0.4 }, opts: render.Options{WaterGlint: true, CoastalFoam: true}},
+	{name: "flood", params: func(p *world.Params) { p.SeaLevel = 0.35 }, opts: render.Options{FloodRise: 0.05, FloodStep: 0.01}},
+	{name: "grid", params: func(p *world.Params) { p.SeaLevel = 0.35 }, opts: render.Options{
+		GridSpacing: 32,
+		Styles:      map[string]render.LayerStyle{render.LayerGrid: {Visible: true, Opacity: 0.5, Mode: render.BlendMultiply}},
+	}},
+	{name: "ridged", params: func(p *world.Params) { p.TerrainStyle = world.StyleRidged }},
+	{name: "coast", params: func(p *world.Params) { p.SeaLevel = 0.35; p.CoastSmoothing = 4 }},
+	{name: "influence", params: func(p *world.Params) { p.SeaLevel = 0.35 }, opts: render.Options{
+		InfluenceRadius: 16,
+		Styles:          map[string]render.LayerStyle{render.LayerInfluence: {Visible: true, Opacity: 0.6, Mode: render.BlendNormal}},
+	}},
+	{name: "influence_fields", params: func(p *world.Params) { p.SeaLevel = 0.35 }, opts: render.Options{
+		InfluenceRadius: 24,
+		InfluenceFields: true,
+		Styles:          map[string]render.LayerStyle{render.LayerInfluence: {Visible: true, Opacity: 0.6, Mode: render.BlendNormal}},
+	}},
+	{name: "highcontrast", params: func(p *world.Params) { p.SeaLevel = 0.35 }, opts: render.Options{HighContrast: true}},
+	{name: "labels", params: func(p *world.Params) { p.SeaLevel = 0.35 }, opts: render.Options{
+		Labels: []render.Label{
+			{Text: "Kraithor Range", X: 80, Y: 40},
+			{Text: "Mount Vel 0.91", X: 60, Y: 100, Peak: true},
+			{Text: "Edge", X: 2, Y: 158},
+		},
+		Styles: map[string]render.LayerStyle{render.LayerLabels: {Visible: true, Opacity: 1, Mode: render.BlendNormal}},
+	}},
+}
+
+func (s scene) render(workers int) image.Image {
+	params := world.DefaultParams(sceneSize, sceneSize)
+	// spread the terrain over the small map like it is over the full-size one
+	params.Scale *= 512.0 / sceneSize
+	params.ContinentFreq *= 512.0 / sceneSize
+	params.FlowScale *= 512.0 / sceneSize
+	params.FlowStrength *= sceneSize / 512.0
+	params.MinDistance = 10
+	if s.params != nil {
+		s.params(&params)
+	}
+	// a world without room for POIs still renders
+	w, _ := world.Generate(params, nil, world.WithWorkers(workers))
+	return render.Render(w, s.opts, nil)
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func main() {
+	dir := flag.String("dir", filepath.Join("testdata", "golden"), "directory holding the golden images")
+	update := flag.Bool("update", false, "overwrite the goldens with the current output")
+	tolerance := flag.Float64("tolerance", 0.02, "perceptual distance in [0,1] below which pixels match")
+	maxFraction := flag.Float64("max-fraction", 0.001, "share of pixels allowed to differ")
+	workers := flag.Int("workers", 0, "goroutines to generate on (0 means one per CPU)")
+	flag.Parse()
+
+	if *update {
+		if err := os.MkdirAll(*dir, 0o755); err != nil {
+			fmt.Println("goldens error:", err)
+			os.Exit(1)
+		}
+	}
+
+	failed := 0
+	for _, s := range scenes {
+		got := s.render(*workers)
+		golden := filepath.Join(*dir, s.name+".png")
+		diffPath := filepath.Join(*dir, s.name+".diff.png")
+
+		if *update {
+			if err := writePNG(golden, got); err != nil {
+				fmt.Println("goldens error:", err)
+				os.Exit(1)
+			}
+			os.Remove(diffPath)
+			fmt.Printf("updated %s\n", golden)
+			continue
+		}
+
+		want, err := readPNG(golden)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("FAIL %s: no golden image, run with -update to create it\n", s.name)
+			failed++
+			continue
+		} else if err != nil {
+			fmt.Printf("FAIL %s: %v\n", s.name, err)
+			failed++
+			continue
+		}
+
+		res, err := imagediff.Compare(got, want, *tolerance)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", s.name, err)
+			failed++
+			continue
+		}
+		if res.Fraction() > *maxFraction {
+			if err := writePNG(diffPath, res.Diff); err != nil {
+				fmt.Println("goldens error:", err)
+			}
+			fmt.Printf("FAIL %s: %d of %d pixels differ (max distance %.3f), see %s\n", s.name, res.Differing, res.Total, res.MaxDistance, diffPath)
+			failed++
+			continue
+		}
+		os.Remove(diffPath)
+		fmt.Printf("ok   %s\n", s.name)
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d of %d scenes failed\n", failed, len(scenes))
+		os.Exit(1)
+	}
+}
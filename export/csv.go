@@ -0,0 +1,41 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ExportGridCSV writes one CSV row per cell of a width x height map: its x
+// and y, then the cell's value in each of grids, which are row-major and
+// named by names in the header.
+func ExportGridCSV(w io.Writer, width, height int, names []string, grids ...[]float64) error {
+	if len(names) != len(grids) {
+		return fmt.Errorf("export: %d names for %d grids", len(names), len(grids))
+	}
+	for i, g := range grids {
+		if len(g) != width*height {
+			return fmt.Errorf("export: grid %q has %d cells, want %d", names[i], len(g), width*height)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"x", "y"}, names...)); err != nil {
+		return err
+	}
+	record := make([]string, 2+len(grids))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			record[0], record[1] = strconv.Itoa(x), strconv.Itoa(y)
+			for i, g := range grids {
+				record[2+i] = strconv.FormatFloat(g[y*width+x], 'g', 6, 64)
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
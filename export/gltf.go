@@ -0,0 +1,324 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"perlin_noise/heightfield"
+)
+
+// glTF constants used by the exporter.
+const (
+	glbMagic     = 0x46546C67 // "glTF"
+	glbVersion   = 2
+	glbChunkJSON = 0x4E4F534A // "JSON"
+	glbChunkBIN  = 0x004E4942 // "BIN\0"
+
+	componentFloat  = 5126
+	componentUint32 = 5125
+
+	targetArrayBuffer        = 34962
+	targetElementArrayBuffer = 34963
+)
+
+type gltfDoc struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes"`
+	Materials   []gltfMaterial   `json:"materials"`
+	Textures    []gltfTexture    `json:"textures"`
+	Images      []gltfImage      `json:"images"`
+	Samplers    []gltfSampler    `json:"samplers"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Buffers     []gltfBuffer     `json:"buffers"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Mesh int `json:"mesh"`
+}
+
+type gltfMesh struct {
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Material   int            `json:"material"`
+}
+
+type gltfMaterial struct {
+	PBR           gltfPBR         `json:"pbrMetallicRoughness"`
+	NormalTexture *gltfTextureRef `json:"normalTexture,omitempty"`
+}
+
+type gltfPBR struct {
+	BaseColorTexture gltfTextureRef `json:"baseColorTexture"`
+	MetallicFactor   float64        `json:"metallicFactor"`
+	RoughnessFactor  float64        `json:"roughnessFactor"`
+}
+
+type gltfTextureRef struct {
+	Index int `json:"index"`
+}
+
+type gltfTexture struct {
+	Sampler int `json:"sampler"`
+	Source  int `json:"source"`
+}
+
+type gltfImage struct {
+	BufferView int    `json:"bufferView"`
+	MimeType   string `json:"mimeType"`
+}
+
+type gltfSampler struct {
+	MagFilter int `json:"magFilter"`
+	MinFilter int `json:"minFilter"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target,omitempty"`
+}
+
+type gltfBuffer struct {
+	ByteLength int `json:"byteLength"`
+}
+
+// glbBuilder accumulates the binary chunk and its buffer views.
+type glbBuilder struct {
+	bin   bytes.Buffer
+	views []gltfBufferView
+}
+
+// add appends data as a new 4-byte aligned buffer view and returns its index.
+func (b *glbBuilder) add(data []byte, target int) int {
+	for b.bin.Len()%4 != 0 {
+		b.bin.WriteByte(0)
+	}
+	b.views = append(b.views, gltfBufferView{
+		Buffer:     0,
+		ByteOffset: b.bin.Len(),
+		ByteLength: len(data),
+		Target:     target,
+	})
+	b.bin.Write(data)
+	return len(b.views) - 1
+}
+
+// gridCoords returns the sample positions 0, step, 2*step, ... always ending at n-1.
+func gridCoords(n, step int) []int {
+	if step < 1 {
+		step = 1
+	}
+	var coords []int
+	for i := 0; i < n-1; i += step {
+		coords = append(coords, i)
+	}
+	return append(coords, n-1)
+}
+
+// heightNormal returns the surface normal of the heightfield at (x, y),
+// using central differences on noiseMap scaled by heightScale.
+func heightNormal(noiseMap *heightfield.Field, width, height, x, y int, heightScale float64) (float64, float64, float64) {
+	at := func(xx, yy int) float64 {
+		if xx < 0 {
+			xx = 0
+		} else if xx >= width {
+			xx = width - 1
+		}
+		if yy < 0 {
+			yy = 0
+		} else if yy >= height {
+			yy = height - 1
+		}
+		return noiseMap.At(xx, yy) * heightScale
+	}
+	dhdx := (at(x+1, y) - at(x-1, y)) * 0.5
+	dhdz := (at(x, y+1) - at(x, y-1)) * 0.5
+
+	nx, ny, nz := -dhdx, 1.0, -dhdz
+	l := math.Sqrt(nx*nx + ny*ny + nz*nz)
+	return nx / l, ny / l, nz / l
+}
+
+// NormalMap bakes a tangent-space normal map of the heightfield, laid out so
+// that it matches the UVs and tangents written by ExportGLB.
+func NormalMap(noiseMap *heightfield.Field, width, height int, heightScale float64) *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			nx, ny, nz := heightNormal(noiseMap, width, height, x, y, heightScale)
+			// tangent = +X, bitangent = -Z (image "up"), normal = +Y
+			out.Set(x, y, color.RGBA{
+				R: uint8(math.Round((nx*0.5 + 0.5) * 255)),
+				G: uint8(math.Round((-nz*0.5 + 0.5) * 255)),
+				B: uint8(math.Round((ny*0.5 + 0.5) * 255)),
+				A: 255,
+			})
+		}
+	}
+	return out
+}
+
+// ExportGLB writes the heightfield as a binary glTF 2.0 (.glb) terrain mesh with
+// texture used as the base color and a baked normal map, both embedded.
+// The mesh samples every step pixels; heights are noise values multiplied by heightScale.
+// Vertex normals point straight up so that all shading comes from the full-resolution
+// normal map rather than the decimated geometry.
+func ExportGLB(w io.Writer, noiseMap *heightfield.Field, width, height int, texture image.Image, step int, heightScale float64) error {
+	xs := gridCoords(width, step)
+	ys := gridCoords(height, step)
+	cols, rows := len(xs), len(ys)
+	vertexCount := cols * rows
+
+	var positions, normals, tangents, uvs bytes.Buffer
+	minPos := []float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	maxPos := []float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+
+	for _, y := range ys {
+		for _, x := range xs {
+			pos := []float32{
+				float32(x),
+				float32(noiseMap.At(x, y) * heightScale),
+				float32(y),
+			}
+			for i, v := range pos {
+				minPos[i] = float32(math.Min(float64(minPos[i]), float64(v)))
+				maxPos[i] = float32(math.Max(float64(maxPos[i]), float64(v)))
+			}
+			binary.Write(&positions, binary.LittleEndian, pos)
+			binary.Write(&normals, binary.LittleEndian, []float32{0, 1, 0})
+			binary.Write(&tangents, binary.LittleEndian, []float32{1, 0, 0, 1})
+			binary.Write(&uvs, binary.LittleEndian, []float32{
+				float32(x) / float32(width-1),
+				float32(y) / float32(height-1),
+			})
+		}
+	}
+
+	var indices bytes.Buffer
+	indexCount := 0
+	for r := 0; r < rows-1; r++ {
+		for c := 0; c < cols-1; c++ {
+			i0 := uint32(r*cols + c)
+			i1 := i0 + 1
+			i2 := i0 + uint32(cols)
+			i3 := i2 + 1
+			// counter-clockwise when seen from +Y
+			binary.Write(&indices, binary.LittleEndian, []uint32{i0, i2, i1, i1, i2, i3})
+			indexCount += 6
+		}
+	}
+
+	var colorPNG, normalPNG bytes.Buffer
+	if err := png.Encode(&colorPNG, texture); err != nil {
+		return err
+	}
+	if err := png.Encode(&normalPNG, NormalMap(noiseMap, width, height, heightScale)); err != nil {
+		return err
+	}
+
+	b := &glbBuilder{}
+	posView := b.add(positions.Bytes(), targetArrayBuffer)
+	normView := b.add(normals.Bytes(), targetArrayBuffer)
+	tanView := b.add(tangents.Bytes(), targetArrayBuffer)
+	uvView := b.add(uvs.Bytes(), targetArrayBuffer)
+	idxView := b.add(indices.Bytes(), targetElementArrayBuffer)
+	colorView := b.add(colorPNG.Bytes(), 0)
+	normalView := b.add(normalPNG.Bytes(), 0)
+	for b.bin.Len()%4 != 0 {
+		b.bin.WriteByte(0)
+	}
+
+	doc := gltfDoc{
+		Asset:  gltfAsset{Version: "2.0", Generator: "perlin_noise"},
+		Scene:  0,
+		Scenes: []gltfScene{{Nodes: []int{0}}},
+		Nodes:  []gltfNode{{Mesh: 0}},
+		Meshes: []gltfMesh{{Primitives: []gltfPrimitive{{
+			Attributes: map[string]int{"POSITION": 0, "NORMAL": 1, "TANGENT": 2, "TEXCOORD_0": 3},
+			Indices:    4,
+			Material:   0,
+		}}}},
+		Materials: []gltfMaterial{{
+			PBR: gltfPBR{
+				BaseColorTexture: gltfTextureRef{Index: 0},
+				MetallicFactor:   0,
+				RoughnessFactor:  1,
+			},
+			NormalTexture: &gltfTextureRef{Index: 1},
+		}},
+		Textures: []gltfTexture{{Sampler: 0, Source: 0}, {Sampler: 0, Source: 1}},
+		Images: []gltfImage{
+			{BufferView: colorView, MimeType: "image/png"},
+			{BufferView: normalView, MimeType: "image/png"},
+		},
+		// LINEAR / LINEAR_MIPMAP_LINEAR
+		Samplers: []gltfSampler{{MagFilter: 9729, MinFilter: 9987}},
+		Accessors: []gltfAccessor{
+			{BufferView: posView, ComponentType: componentFloat, Count: vertexCount, Type: "VEC3", Min: minPos, Max: maxPos},
+			{BufferView: normView, ComponentType: componentFloat, Count: vertexCount, Type: "VEC3"},
+			{BufferView: tanView, ComponentType: componentFloat, Count: vertexCount, Type: "VEC4"},
+			{BufferView: uvView, ComponentType: componentFloat, Count: vertexCount, Type: "VEC2"},
+			{BufferView: idxView, ComponentType: componentUint32, Count: indexCount, Type: "SCALAR"},
+		},
+		BufferViews: b.views,
+		Buffers:     []gltfBuffer{{ByteLength: b.bin.Len()}},
+	}
+
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	for len(jsonData)%4 != 0 {
+		jsonData = append(jsonData, ' ')
+	}
+
+	total := 12 + 8 + len(jsonData) + 8 + b.bin.Len()
+	header := []uint32{
+		glbMagic, glbVersion, uint32(total),
+		uint32(len(jsonData)), glbChunkJSON,
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if _, err := w.Write(jsonData); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, []uint32{uint32(b.bin.Len()), glbChunkBIN}); err != nil {
+		return err
+	}
+	_, err = w.Write(b.bin.Bytes())
+	return err
+}
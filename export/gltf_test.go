@@ -0,0 +1,75 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"testing"
+
+	"perlin_noise/heightfield"
+)
+
+// TestExportGLBRoundTrip parses the GLB of a 3x3 field: the header and both
+// chunk headers must add up, the JSON chunk must decode, and the accessors
+// must count 9 vertices and 8 triangles, each fitting in its buffer view.
+func TestExportGLBRoundTrip(t *testing.T) {
+	f := heightfield.New(3, 3)
+	for i := range f.Data {
+		f.Data[i] = float64(i) / 8
+	}
+	var buf bytes.Buffer
+	if err := ExportGLB(&buf, f, 3, 3, image.NewRGBA(image.Rect(0, 0, 3, 3)), 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	le := binary.LittleEndian
+	if magic, version, length := le.Uint32(data), le.Uint32(data[4:]), le.Uint32(data[8:]); magic != glbMagic || version != glbVersion || int(length) != len(data) {
+		t.Fatalf("header magic %#x version %d length %d, file is %d bytes", magic, version, length, len(data))
+	}
+	jsonLen := int(le.Uint32(data[12:]))
+	if le.Uint32(data[16:]) != glbChunkJSON || jsonLen%4 != 0 {
+		t.Fatalf("first chunk is not a 4-byte aligned JSON chunk")
+	}
+	var doc gltfDoc
+	if err := json.Unmarshal(data[20:20+jsonLen], &doc); err != nil {
+		t.Fatal(err)
+	}
+	binStart := 20 + jsonLen
+	binLen := int(le.Uint32(data[binStart:]))
+	if le.Uint32(data[binStart+4:]) != glbChunkBIN || binStart+8+binLen != len(data) {
+		t.Fatalf("second chunk is not a BIN chunk ending the file")
+	}
+	if len(doc.Buffers) != 1 || doc.Buffers[0].ByteLength != binLen {
+		t.Fatalf("buffers %+v, BIN chunk is %d bytes", doc.Buffers, binLen)
+	}
+
+	prim := doc.Meshes[0].Primitives[0]
+	// components per element, all 4 bytes wide
+	width := map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4}
+	tests := []struct {
+		accessor int
+		count    int
+	}{
+		{prim.Attributes["POSITION"], 9},
+		{prim.Attributes["NORMAL"], 9},
+		{prim.Attributes["TANGENT"], 9},
+		{prim.Attributes["TEXCOORD_0"], 9},
+		{prim.Indices, 8 * 3},
+	}
+	for _, tt := range tests {
+		a := doc.Accessors[tt.accessor]
+		if a.Count != tt.count {
+			t.Errorf("accessor %d counts %d, want %d", tt.accessor, a.Count, tt.count)
+		}
+		v := doc.BufferViews[a.BufferView]
+		if v.ByteOffset+v.ByteLength > binLen || v.ByteLength != a.Count*width[a.Type]*4 {
+			t.Errorf("accessor %d: %d %s in view %+v", tt.accessor, a.Count, a.Type, v)
+		}
+	}
+	pos := doc.Accessors[prim.Attributes["POSITION"]]
+	if pos.Min[1] != 0 || pos.Max[1] != 10 {
+		t.Errorf("height range %v to %v, want 0 to 10", pos.Min[1], pos.Max[1])
+	}
+}
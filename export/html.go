@@ -0,0 +1,168 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html/template"
+	"image"
+	"image/png"
+	"io"
+	"strconv"
+
+	"perlin_noise/heightfield"
+	"perlin_noise/poi"
+)
+
+// htmlPOI is the data a POI popup shows.
+type htmlPOI struct {
+	Name      string  `json:"name"`
+	X         int     `json:"x"`
+	Y         int     `json:"y"`
+	Elevation float64 `json:"elevation"`
+	AboveSea  float64 `json:"aboveSea"`
+	KmX       float64 `json:"kmX"`
+	KmY       float64 `json:"kmY"`
+}
+
+var htmlTemplate = template.Must(template.New("map").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+  html, body { margin: 0; height: 100%; overflow: hidden; background: #10202c; font: 14px sans-serif; }
+  #viewport { position: absolute; inset: 0; cursor: grab; }
+  #viewport.dragging { cursor: grabbing; }
+  #map { position: absolute; left: 0; top: 0; transform-origin: 0 0; }
+  #map img { display: block; image-rendering: pixelated; user-select: none; -webkit-user-drag: none; }
+  .poi { position: absolute; width: 10px; height: 10px; margin: -5px 0 0 -5px; border-radius: 50%;
+         background: #e22; border: 1px solid #fff; cursor: pointer; }
+  #popup { position: absolute; display: none; padding: 8px 10px; background: #fff; border-radius: 4px;
+           box-shadow: 0 2px 8px rgba(0,0,0,.4); pointer-events: none; white-space: nowrap; }
+  #popup b { display: block; margin-bottom: 4px; }
+  #help { position: absolute; left: 8px; bottom: 8px; color: #cde; opacity: .8; }
+</style>
+</head>
+<body>
+<div id="viewport">
+  <div id="map"><img src="{{.Image}}" width="{{.Width}}" height="{{.Height}}" alt="{{.Title}}"></div>
+</div>
+<div id="popup"></div>
+<div id="help">Drag to pan, scroll to zoom, click a point of interest for details.</div>
+<script>
+(function () {
+  var pois = {{.POIs}};
+  var viewport = document.getElementById("viewport");
+  var map = document.getElementById("map");
+  var popup = document.getElementById("popup");
+  var scale = Math.min(innerWidth / {{.Width}}, innerHeight / {{.Height}});
+  var x = (innerWidth - {{.Width}} * scale) / 2, y = (innerHeight - {{.Height}} * scale) / 2;
+
+  function apply() {
+    map.style.transform = "translate(" + x + "px," + y + "px) scale(" + scale + ")";
+    var markers = map.querySelectorAll(".poi");
+    for (var i = 0; i < markers.length; i++) {
+      // keep markers the same size on screen at every zoom
+      markers[i].style.transform = "scale(" + 1 / scale + ")";
+    }
+  }
+
+  pois.forEach(function (p) {
+    var m = document.createElement("div");
+    m.className = "poi";
+    m.style.left = (p.x + 0.5) + "px";
+    m.style.top = (p.y + 0.5) + "px";
+    m.addEventListener("click", function (e) {
+      e.stopPropagation();
+      popup.innerHTML = "";
+      var title = document.createElement("b");
+      title.textContent = p.name;
+      popup.appendChild(title);
+      [
+        "Position: " + p.x + ", " + p.y + " px",
+        "Position: " + p.kmX.toFixed(1) + ", " + p.kmY.toFixed(1) + " km",
+        "Elevation: " + p.elevation.toFixed(3),
+        "Above sea level: " + p.aboveSea.toFixed(3)
+      ].forEach(function (line) {
+        popup.appendChild(document.createTextNode(line));
+        popup.appendChild(document.createElement("br"));
+      });
+      popup.style.left = (e.clientX + 12) + "px";
+      popup.style.top = (e.clientY + 12) + "px";
+      popup.style.display = "block";
+    });
+    map.appendChild(m);
+  });
+
+  var drag = null;
+  viewport.addEventListener("mousedown", function (e) {
+    drag = { x: e.clientX - x, y: e.clientY - y };
+    viewport.classList.add("dragging");
+    popup.style.display = "none";
+  });
+  addEventListener("mousemove", function (e) {
+    if (!drag) return;
+    x = e.clientX - drag.x;
+    y = e.clientY - drag.y;
+    apply();
+  });
+  addEventListener("mouseup", function () {
+    drag = null;
+    viewport.classList.remove("dragging");
+  });
+  viewport.addEventListener("wheel", function (e) {
+    e.preventDefault();
+    // zoom around the cursor
+    var f = e.deltaY < 0 ? 1.2 : 1 / 1.2;
+    var next = Math.min(Math.max(scale * f, 0.1), 40);
+    x = e.clientX - (e.clientX - x) * next / scale;
+    y = e.clientY - (e.clientY - y) * next / scale;
+    scale = next;
+    popup.style.display = "none";
+    apply();
+  }, { passive: false });
+
+  apply();
+})();
+</script>
+</body>
+</html>
+`))
+
+// ExportHTML writes a self-contained interactive web page showing mapImage with
+// pan and zoom. Each POI is a clickable marker whose popup gives its position,
+// in pixels and in kilometres at kmPerPixel, and its elevation. The image is
+// embedded as a PNG, so the page needs no other files or network access.
+func ExportHTML(w io.Writer, title string, mapImage image.Image, pois []poi.Point, noiseMap *heightfield.Field, seaLevel, kmPerPixel float64) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, mapImage); err != nil {
+		return err
+	}
+
+	markers := make([]htmlPOI, len(pois))
+	for i, p := range pois {
+		markers[i] = htmlPOI{
+			Name:      "Point of interest " + strconv.Itoa(i+1),
+			X:         p.X,
+			Y:         p.Y,
+			Elevation: noiseMap.At(p.X, p.Y),
+			AboveSea:  noiseMap.At(p.X, p.Y) - seaLevel,
+			KmX:       float64(p.X) * kmPerPixel,
+			KmY:       float64(p.Y) * kmPerPixel,
+		}
+	}
+
+	bounds := mapImage.Bounds()
+	return htmlTemplate.Execute(w, struct {
+		Title         string
+		Image         template.URL
+		Width, Height int
+		POIs          []htmlPOI
+	}{
+		Title:  title,
+		Image:  template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())),
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+		POIs:   markers,
+	})
+}
@@ -0,0 +1,146 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"perlin_noise/heightfield"
+)
+
+type vec3 [3]float32
+
+func (a vec3) sub(b vec3) vec3 {
+	return vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func (a vec3) cross(b vec3) vec3 {
+	return vec3{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+func (a vec3) dot(b vec3) float32 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+// stlWriter collects facets for a binary STL file.
+type stlWriter struct {
+	facets [][4]vec3
+}
+
+// facet adds triangle a,b,c, flipping its winding if needed so that its
+// normal points along out.
+func (s *stlWriter) facet(a, b, c, out vec3) {
+	n := b.sub(a).cross(c.sub(a))
+	if n.dot(out) < 0 {
+		b, c = c, b
+		n = vec3{-n[0], -n[1], -n[2]}
+	}
+	l := float32(math.Sqrt(float64(n.dot(n))))
+	if l > 0 {
+		n = vec3{n[0] / l, n[1] / l, n[2] / l}
+	}
+	s.facets = append(s.facets, [4]vec3{n, a, b, c})
+}
+
+// quad adds the quad a,b,c,d (in order around its edge) as two facets.
+func (s *stlWriter) quad(a, b, c, d, out vec3) {
+	s.facet(a, b, c, out)
+	s.facet(a, c, d, out)
+}
+
+func (s *stlWriter) writeTo(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	header := make([]byte, 80)
+	copy(header, "perlin_noise terrain")
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, binary.LittleEndian, uint32(len(s.facets))); err != nil {
+		return err
+	}
+	for _, f := range s.facets {
+		if err := binary.Write(bw, binary.LittleEndian, f); err != nil {
+			return err
+		}
+		// attribute byte count
+		if err := binary.Write(bw, binary.LittleEndian, uint16(0)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ExportSTL writes the heightfield as a watertight binary STL solid ready for 3D printing:
+// the terrain surface on top, a flat base underneath and side walls joining them.
+// The longest map edge is scaled to printSize millimetres and the base is baseThickness mm thick.
+// Heights are noise values multiplied by heightScale (in map pixels, as for ExportGLB) and
+// by exaggeration. The mesh samples every step pixels. The map must be at least
+// 2x2 pixels, step at least 1 and printSize positive.
+func ExportSTL(w io.Writer, noiseMap *heightfield.Field, width, height, step int, printSize, baseThickness, heightScale, exaggeration float64) error {
+	// a single row or column of pixels has no surface to print
+	if min(width, height) < 2 {
+		return fmt.Errorf("export: a %dx%d map is too small to print", width, height)
+	}
+	if step < 1 {
+		return fmt.Errorf("export: step must be at least 1, got %d", step)
+	}
+	if !(printSize > 0) {
+		return fmt.Errorf("export: print size must be positive, got %v", printSize)
+	}
+
+	xs := gridCoords(width, step)
+	ys := gridCoords(height, step)
+	cols, rows := len(xs), len(ys)
+
+	mmPerPixel := printSize / float64(max(width, height)-1)
+
+	// top[r][c] is the terrain vertex, bottom[r][c] the matching base vertex.
+	// Image rows grow downwards, so flip Y to keep the print the right way round.
+	top := make([][]vec3, rows)
+	bottom := make([][]vec3, rows)
+	for r, y := range ys {
+		top[r] = make([]vec3, cols)
+		bottom[r] = make([]vec3, cols)
+		for c, x := range xs {
+			px := float32(float64(x) * mmPerPixel)
+			py := float32(float64(height-1-y) * mmPerPixel)
+			pz := float32(baseThickness + noiseMap.At(x, y)*heightScale*exaggeration*mmPerPixel)
+			top[r][c] = vec3{px, py, pz}
+			bottom[r][c] = vec3{px, py, 0}
+		}
+	}
+
+	s := &stlWriter{}
+	up := vec3{0, 0, 1}
+	down := vec3{0, 0, -1}
+
+	// Top surface and base share the same grid so every edge is matched.
+	for r := 0; r < rows-1; r++ {
+		for c := 0; c < cols-1; c++ {
+			s.quad(top[r][c], top[r][c+1], top[r+1][c+1], top[r+1][c], up)
+			s.quad(bottom[r][c], bottom[r][c+1], bottom[r+1][c+1], bottom[r+1][c], down)
+		}
+	}
+
+	// Side walls along the four edges.
+	north := vec3{0, 1, 0}
+	south := vec3{0, -1, 0}
+	west := vec3{-1, 0, 0}
+	east := vec3{1, 0, 0}
+	for c := 0; c < cols-1; c++ {
+		s.quad(top[0][c], top[0][c+1], bottom[0][c+1], bottom[0][c], north)
+		s.quad(top[rows-1][c], top[rows-1][c+1], bottom[rows-1][c+1], bottom[rows-1][c], south)
+	}
+	for r := 0; r < rows-1; r++ {
+		s.quad(top[r][0], top[r+1][0], bottom[r+1][0], bottom[r][0], west)
+		s.quad(top[r][cols-1], top[r+1][cols-1], bottom[r+1][cols-1], bottom[r][cols-1], east)
+	}
+
+	return s.writeTo(w)
+}
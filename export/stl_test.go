@@ -0,0 +1,109 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"perlin_noise/heightfield"
+)
+
+// readSTL parses a binary STL into its facets: the normal, then the three
+// corners.
+func readSTL(t *testing.T, data []byte) [][4]vec3 {
+	t.Helper()
+	r := bytes.NewReader(data[80:])
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		t.Fatal(err)
+	}
+	facets := make([][4]vec3, count)
+	for i := range facets {
+		var attr uint16
+		if err := binary.Read(r, binary.LittleEndian, &facets[i]); err != nil {
+			t.Fatal(err)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &attr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.Len() != 0 {
+		t.Fatalf("%d bytes left after %d facets", r.Len(), count)
+	}
+	return facets
+}
+
+// TestExportSTLWatertight checks on a small grid that every edge is shared
+// by exactly two triangles, traversed once in each direction, and that the
+// solid encloses a positive volume. Together these mean every triangle faces
+// outward. Each stored normal must match its triangle's winding.
+func TestExportSTLWatertight(t *testing.T) {
+	f := heightfield.New(5, 4)
+	for i := range f.Data {
+		f.Data[i] = float64(i%7) / 7
+	}
+	for _, step := range []int{1, 2, 10} {
+		var buf bytes.Buffer
+		if err := ExportSTL(&buf, f, 5, 4, step, 40, 2, 3, 1.5); err != nil {
+			t.Fatal(err)
+		}
+		facets := readSTL(t, buf.Bytes())
+
+		edges := map[[2]vec3]int{}
+		volume := 0.0
+		for _, fc := range facets {
+			n, a, b, c := fc[0], fc[1], fc[2], fc[3]
+			edges[[2]vec3{a, b}]++
+			edges[[2]vec3{b, c}]++
+			edges[[2]vec3{c, a}]++
+
+			want := b.sub(a).cross(c.sub(a))
+			l := float32(math.Sqrt(float64(want.dot(want))))
+			if l == 0 {
+				t.Fatalf("step %d: degenerate facet %v", step, fc)
+			}
+			if d := n.dot(want) / l; d < 0.999 {
+				t.Fatalf("step %d: normal %v does not match winding %v", step, n, want)
+			}
+			volume += float64(a.dot(b.cross(c))) / 6
+		}
+		for e, count := range edges {
+			if count != 1 || edges[[2]vec3{e[1], e[0]}] != 1 {
+				t.Fatalf("step %d: edge %v used %d times, reverse %d times", step, e, count, edges[[2]vec3{e[1], e[0]}])
+			}
+		}
+		if volume <= 0 {
+			t.Fatalf("step %d: volume %v, want positive", step, volume)
+		}
+	}
+}
+
+// TestExportSTLInvalid checks that maps too small to print, a step below 1
+// and a non-positive print size are errors rather than Inf or NaN vertices.
+func TestExportSTLInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		step          int
+		printSize     float64
+	}{
+		{"one pixel", 1, 1, 1, 100},
+		{"one row", 8, 1, 1, 100},
+		{"one column", 1, 8, 1, 100},
+		{"zero step", 4, 4, 0, 100},
+		{"zero print size", 4, 4, 1, 0},
+		{"negative print size", 4, 4, 1, -10},
+		{"NaN print size", 4, 4, 1, math.NaN()},
+	}
+	for _, tt := range tests {
+		f := heightfield.New(tt.width, tt.height)
+		var buf bytes.Buffer
+		if err := ExportSTL(&buf, f, tt.width, tt.height, tt.step, tt.printSize, 2, 3, 1); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: wrote %d bytes", tt.name, buf.Len())
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Package geom provides the 2D geometry shared by vector features such as
+// coastlines, regions, roads and labels. Coordinates are in pixels with y
+// pointing down, as on screen.
+package geom
+
+import "math"
+
+// Point is a position in pixel coordinates.
+type Point struct {
+	X, Y float64
+}
+
+// Dist returns the distance between p and q.
+func (p Point) Dist(q Point) float64 {
+	return math.Hypot(p.X-q.X, p.Y-q.Y)
+}
+
+// lerp returns the point a fraction t of the way from p to q.
+func (p Point) lerp(q Point, t float64) Point {
+	return Point{X: p.X + (q.X-p.X)*t, Y: p.Y + (q.Y-p.Y)*t}
+}
+
+// segmentDist returns the distance from p to the segment ab.
+func segmentDist(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return p.Dist(a)
+	}
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / l2
+	return p.Dist(a.lerp(b, max(0, min(1, t))))
+}
+
+// Polyline is an open chain of points.
+type Polyline []Point
+
+// Length returns the total length of the chain.
+func (l Polyline) Length() float64 {
+	total := 0.0
+	for i := 1; i < len(l); i++ {
+		total += l[i-1].Dist(l[i])
+	}
+	return total
+}
+
+// Simplify reduces the chain with Douglas–Peucker, keeping both ends and every
+// point that lies further than tolerance from the simplified chain.
+func (l Polyline) Simplify(tolerance float64) Polyline {
+	if len(l) < 3 {
+		return append(Polyline{}, l...)
+	}
+	a, b := l[0], l[len(l)-1]
+	worst, worstDist := 0, 0.0
+	for i := 1; i < len(l)-1; i++ {
+		if d := segmentDist(l[i], a, b); d > worstDist {
+			worst, worstDist = i, d
+		}
+	}
+	if worstDist <= tolerance {
+		return Polyline{a, b}
+	}
+	left := l[:worst+1].Simplify(tolerance)
+	right := l[worst:].Simplify(tolerance)
+	return append(left[:len(left)-1], right...)
+}
+
+// Smooth rounds the chain's corners with the given number of Chaikin
+// iterations, keeping both ends in place.
+func (l Polyline) Smooth(iterations int) Polyline {
+	out := append(Polyline{}, l...)
+	for range iterations {
+		if len(out) < 3 {
+			break
+		}
+		next := Polyline{out[0]}
+		for i := 0; i < len(out)-1; i++ {
+			next = append(next, out[i].lerp(out[i+1], 0.25), out[i].lerp(out[i+1], 0.75))
+		}
+		out = append(next, out[len(out)-1])
+	}
+	return out
+}
@@ -0,0 +1,28 @@
+package geom
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestPolylineSimplify checks Douglas–Peucker on open chains: collinear and
+// near-collinear points go, corners further than the tolerance stay.
+func TestPolylineSimplify(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Polyline
+		want Polyline
+	}{
+		{"empty", Polyline{}, Polyline{}},
+		{"two points", Polyline{{0, 0}, {5, 0}}, Polyline{{0, 0}, {5, 0}}},
+		{"collinear", Polyline{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}}, Polyline{{0, 0}, {4, 0}}},
+		{"collinear diagonal", Polyline{{0, 0}, {1, 1}, {2, 2}, {5, 5}}, Polyline{{0, 0}, {5, 5}}},
+		{"within tolerance", Polyline{{0, 0}, {1, 0.4}, {2, -0.4}, {3, 0}}, Polyline{{0, 0}, {3, 0}}},
+		{"corner", Polyline{{0, 0}, {1, 0}, {2, 0}, {2, 1}, {2, 2}}, Polyline{{0, 0}, {2, 0}, {2, 2}}},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Simplify(0.5); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Simplify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package geom
+
+// Polygon is a closed ring of points; the last point connects back to the
+// first and is not repeated.
+type Polygon []Point
+
+// Area returns the signed area of the ring: positive when it runs clockwise
+// on screen, negative when it runs counterclockwise.
+func (p Polygon) Area() float64 {
+	sum := 0.0
+	for i := range p {
+		q := p[(i+1)%len(p)]
+		sum += p[i].X*q.Y - q.X*p[i].Y
+	}
+	return sum / 2
+}
+
+// Perimeter returns the length of the ring, including the closing edge.
+func (p Polygon) Perimeter() float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	return Polyline(p).Length() + p[len(p)-1].Dist(p[0])
+}
+
+// Centroid returns the center of mass of the ring's area, or the mean of its
+// points if the area is zero.
+func (p Polygon) Centroid() Point {
+	var c Point
+	a := p.Area()
+	if a == 0 {
+		for _, pt := range p {
+			c.X += pt.X / float64(len(p))
+			c.Y += pt.Y / float64(len(p))
+		}
+		return c
+	}
+	for i := range p {
+		q := p[(i+1)%len(p)]
+		cross := p[i].X*q.Y - q.X*p[i].Y
+		c.X += (p[i].X + q.X) * cross
+		c.Y += (p[i].Y + q.Y) * cross
+	}
+	c.X /= 6 * a
+	c.Y /= 6 * a
+	return c
+}
+
+// Bounds returns the corners of the ring's axis-aligned bounding box.
+func (p Polygon) Bounds() (minPt, maxPt Point) {
+	if len(p) == 0 {
+		return
+	}
+	minPt, maxPt = p[0], p[0]
+	for _, pt := range p[1:] {
+		minPt.X, minPt.Y = min(minPt.X, pt.X), min(minPt.Y, pt.Y)
+		maxPt.X, maxPt.Y = max(maxPt.X, pt.X), max(maxPt.Y, pt.Y)
+	}
+	return minPt, maxPt
+}
+
+// Contains reports whether pt lies inside the ring, by the even-odd rule.
+func (p Polygon) Contains(pt Point) bool {
+	inside := false
+	for i, j := 0, len(p)-1; i < len(p); j, i = i, i+1 {
+		a, b := p[i], p[j]
+		if (a.Y > pt.Y) != (b.Y > pt.Y) && pt.X < a.X+(pt.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Simplify reduces the ring with Douglas–Peucker, keeping every point that
+// lies further than tolerance from the simplified outline. A ring whose
+// points all coincide reduces to that one point.
+func (p Polygon) Simplify(tolerance float64) Polygon {
+	if len(p) < 3 {
+		return append(Polygon{}, p...)
+	}
+	// split the ring at the point furthest from the first one
+	far := 0
+	for i, pt := range p {
+		if pt.Dist(p[0]) > p[far].Dist(p[0]) {
+			far = i
+		}
+	}
+	if far == 0 {
+		// there is nothing to split
+		return Polygon{p[0]}
+	}
+	closed := append(Polyline(append(Polygon{}, p...)), p[0])
+	first := closed[:far+1].Simplify(tolerance)
+	second := closed[far:].Simplify(tolerance)
+	// drop the shared split point and the repeated start
+	return Polygon(append(first[:len(first)-1], second[:len(second)-1]...))
+}
+
+// Smooth rounds every corner of the ring with the given number of Chaikin
+// iterations; each one doubles the number of points.
+func (p Polygon) Smooth(iterations int) Polygon {
+	out := append(Polygon{}, p...)
+	for range iterations {
+		if len(out) < 3 {
+			break
+		}
+		next := make(Polygon, 0, 2*len(out))
+		for i := range out {
+			a, b := out[i], out[(i+1)%len(out)]
+			next = append(next, a.lerp(b, 0.25), a.lerp(b, 0.75))
+		}
+		out = next
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package geom
+
+import (
+	"slices"
+	"testing"
+)
+
+// square is the 2x2 square at the origin, clockwise on screen (y down).
+var square = Polygon{{0, 0}, {2, 0}, {2, 2}, {0, 2}}
+
+// TestPolygonArea checks the sign of the area for both windings.
+func TestPolygonArea(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Polygon
+		want float64
+	}{
+		{"empty", Polygon{}, 0},
+		{"clockwise square", square, 4},
+		{"counterclockwise square", Polygon{{0, 0}, {0, 2}, {2, 2}, {2, 0}}, -4},
+		{"clockwise triangle", Polygon{{0, 0}, {4, 0}, {0, 3}}, 6},
+		{"collinear", Polygon{{0, 0}, {1, 1}, {2, 2}}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Area(); got != tt.want {
+			t.Errorf("%s: Area() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestPolygonCentroid checks the center of mass, whatever the winding, and
+// the mean of the points for a ring without area.
+func TestPolygonCentroid(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Polygon
+		want Point
+	}{
+		{"square", square, Point{1, 1}},
+		{"reversed square", Polygon{{0, 2}, {2, 2}, {2, 0}, {0, 0}}, Point{1, 1}},
+		{"triangle", Polygon{{0, 0}, {6, 0}, {0, 3}}, Point{2, 1}},
+		// the mean of the points would be pulled towards the crowded corner
+		{"L-shape", Polygon{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}}, Point{5.0 / 6, 5.0 / 6}},
+		{"flat", Polygon{{0, 0}, {1, 0}, {5, 0}}, Point{2, 0}},
+	}
+	for _, tt := range tests {
+		got := tt.p.Centroid()
+		if d := got.Dist(tt.want); d > 1e-12 {
+			t.Errorf("%s: Centroid() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestPolygonContains checks points inside, outside and in the notch of a
+// concave ring.
+func TestPolygonContains(t *testing.T) {
+	// a U open at the top: the notch between its arms is outside
+	u := Polygon{{0, 0}, {1, 0}, {1, 2}, {2, 2}, {2, 0}, {3, 0}, {3, 3}, {0, 3}}
+	tests := []struct {
+		name string
+		p    Polygon
+		pt   Point
+		want bool
+	}{
+		{"square center", square, Point{1, 1}, true},
+		{"square outside", square, Point{3, 1}, false},
+		{"square above", square, Point{1, -1}, false},
+		{"U left arm", u, Point{0.5, 1}, true},
+		{"U notch", u, Point{1.5, 1}, false},
+		{"U base", u, Point{1.5, 2.5}, true},
+		{"empty", Polygon{}, Point{0, 0}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Contains(tt.pt); got != tt.want {
+			t.Errorf("%s: Contains(%v) = %v, want %v", tt.name, tt.pt, got, tt.want)
+		}
+	}
+}
+
+// TestPolygonSimplify checks Douglas–Peucker on rings, including rings that
+// collapse to a line or a single point.
+func TestPolygonSimplify(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Polygon
+		want Polygon
+	}{
+		{"square kept", square, square},
+		{"square with midpoints", Polygon{{0, 0}, {1, 0}, {2, 0}, {2, 1}, {2, 2}, {1, 2}, {0, 2}, {0, 1}}, square},
+		{"coincident", Polygon{{3, 4}, {3, 4}, {3, 4}, {3, 4}}, Polygon{{3, 4}}},
+		{"back and forth", Polygon{{0, 0}, {1, 0}, {2, 0}, {1, 0}}, Polygon{{0, 0}, {2, 0}}},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Simplify(0.5); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Simplify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
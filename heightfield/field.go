@@ -0,0 +1,26 @@
+// Package heightfield stores height grids densely, one value per cell in
+// row-major order, instead of in a map keyed by point.
+package heightfield
+
+// Field is a width x height grid of float64 heights, used while a world is
+// generated and analysed. Its statistics and normalization helpers are in
+// stats.go.
+type Field struct {
+	Width, Height int
+	Data          []float64
+}
+
+// New returns a zeroed width x height Field.
+func New(width, height int) *Field {
+	return &Field{Width: width, Height: height, Data: make([]float64, width*height)}
+}
+
+// At returns the height of cell (x, y).
+func (f *Field) At(x, y int) float64 {
+	return f.Data[y*f.Width+x]
+}
+
+// Set stores the height of cell (x, y).
+func (f *Field) Set(x, y int, v float64) {
+	f.Data[y*f.Width+x] = v
+}
@@ -0,0 +1,107 @@
+package heightfield
+
+import (
+	"math"
+	"slices"
+)
+
+// Min returns the lowest height of f, or 0 if f is empty.
+func (f *Field) Min() float64 {
+	if len(f.Data) == 0 {
+		return 0
+	}
+	return slices.Min(f.Data)
+}
+
+// Max returns the highest height of f, or 0 if f is empty.
+func (f *Field) Max() float64 {
+	if len(f.Data) == 0 {
+		return 0
+	}
+	return slices.Max(f.Data)
+}
+
+// Mean returns the average height of f, or 0 if f is empty.
+func (f *Field) Mean() float64 {
+	if len(f.Data) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range f.Data {
+		sum += v
+	}
+	return sum / float64(len(f.Data))
+}
+
+// Percentile returns the height below which p percent of the cells lie,
+// interpolating between cells; p is clamped to [0, 100]. It returns 0 if f
+// is empty and NaN if p is NaN.
+func (f *Field) Percentile(p float64) float64 {
+	if len(f.Data) == 0 {
+		return 0
+	}
+	if math.IsNaN(p) {
+		return math.NaN()
+	}
+	sorted := slices.Clone(f.Data)
+	slices.Sort(sorted)
+	pos := min(max(p, 0), 100) / 100 * float64(len(sorted)-1)
+	i := int(pos)
+	if i == len(sorted)-1 {
+		return sorted[i]
+	}
+	return sorted[i] + (sorted[i+1]-sorted[i])*(pos-float64(i))
+}
+
+// Normalize rescales f in place so its heights span [0, 1]. A flat field
+// becomes all zeros.
+func (f *Field) Normalize() {
+	lo, hi := f.Min(), f.Max()
+	if hi == lo {
+		clear(f.Data)
+		return
+	}
+	for i, v := range f.Data {
+		f.Data[i] = (v - lo) / (hi - lo)
+	}
+}
+
+// Clamp limits every height of f to [lo, hi] in place.
+func (f *Field) Clamp(lo, hi float64) {
+	for i, v := range f.Data {
+		f.Data[i] = min(max(v, lo), hi)
+	}
+}
+
+// Resample returns f scaled to width x height with bilinear interpolation.
+// The corner cells of both fields line up, so the edges are kept exactly.
+func (f *Field) Resample(width, height int) *Field {
+	out := New(width, height)
+	if f.Width == 0 || f.Height == 0 {
+		return out
+	}
+	// maps a cell of out to its position in f
+	scale := func(n, from int) float64 {
+		if n <= 1 {
+			return 0
+		}
+		return float64(from-1) / float64(n-1)
+	}
+	sx, sy := scale(width, f.Width), scale(height, f.Height)
+	for y := 0; y < height; y++ {
+		fy := float64(y) * sy
+		y0 := int(fy)
+		y1 := min(y0+1, f.Height-1)
+		ty := fy - math.Floor(fy)
+		for x := 0; x < width; x++ {
+			fx := float64(x) * sx
+			x0 := int(fx)
+			x1 := min(x0+1, f.Width-1)
+			tx := fx - math.Floor(fx)
+			top := f.At(x0, y0) + (f.At(x1, y0)-f.At(x0, y0))*tx
+			bottom := f.At(x0, y1) + (f.At(x1, y1)-f.At(x0, y1))*tx
+			out.Set(x, y, top+(bottom-top)*ty)
+		}
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package heightfield
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestStats checks Min, Max, Mean and Percentile on empty, flat,
+// single-cell and ramp fields, including out-of-range and NaN percentiles.
+func TestStats(t *testing.T) {
+	ramp := &Field{Width: 5, Height: 1, Data: []float64{4, 0, 3, 1, 2}}
+	tests := []struct {
+		name           string
+		f              *Field
+		min, max, mean float64
+		p              float64
+		percentile     float64
+	}{
+		{"empty", New(0, 0), 0, 0, 0, 50, 0},
+		{"flat", &Field{Width: 2, Height: 2, Data: []float64{0.3, 0.3, 0.3, 0.3}}, 0.3, 0.3, 0.3, 75, 0.3},
+		{"single cell", &Field{Width: 1, Height: 1, Data: []float64{0.7}}, 0.7, 0.7, 0.7, 100, 0.7},
+		{"ramp median", ramp, 0, 4, 2, 50, 2},
+		{"ramp between cells", ramp, 0, 4, 2, 60, 2.4},
+		{"ramp below 0", ramp, 0, 4, 2, -10, 0},
+		{"ramp above 100", ramp, 0, 4, 2, 150, 4},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Min(); got != tt.min {
+			t.Errorf("%s: Min() = %v, want %v", tt.name, got, tt.min)
+		}
+		if got := tt.f.Max(); got != tt.max {
+			t.Errorf("%s: Max() = %v, want %v", tt.name, got, tt.max)
+		}
+		if got := tt.f.Mean(); math.Abs(got-tt.mean) > 1e-12 {
+			t.Errorf("%s: Mean() = %v, want %v", tt.name, got, tt.mean)
+		}
+		if got := tt.f.Percentile(tt.p); math.Abs(got-tt.percentile) > 1e-12 {
+			t.Errorf("%s: Percentile(%v) = %v, want %v", tt.name, tt.p, got, tt.percentile)
+		}
+	}
+
+	if got := ramp.Percentile(math.NaN()); !math.IsNaN(got) {
+		t.Errorf("Percentile(NaN) = %v, want NaN", got)
+	}
+	if !slices.Equal(ramp.Data, []float64{4, 0, 3, 1, 2}) {
+		t.Errorf("Percentile reordered the field: %v", ramp.Data)
+	}
+}
+
+// TestNormalizeClamp checks Normalize and Clamp in place, including on empty
+// and flat fields.
+func TestNormalizeClamp(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []float64
+		normalized []float64
+		clamped    []float64 // of the original data, to [0, 1]
+	}{
+		{"empty", []float64{}, []float64{}, []float64{}},
+		{"flat", []float64{2, 2, 2}, []float64{0, 0, 0}, []float64{1, 1, 1}},
+		{"single cell", []float64{-3}, []float64{0}, []float64{0}},
+		{"spread", []float64{-1, 1, 3}, []float64{0, 0.5, 1}, []float64{0, 1, 1}},
+	}
+	for _, tt := range tests {
+		f := &Field{Width: len(tt.data), Height: 1, Data: slices.Clone(tt.data)}
+		f.Normalize()
+		if !slices.Equal(f.Data, tt.normalized) {
+			t.Errorf("%s: Normalize() gives %v, want %v", tt.name, f.Data, tt.normalized)
+		}
+		f = &Field{Width: len(tt.data), Height: 1, Data: slices.Clone(tt.data)}
+		f.Clamp(0, 1)
+		if !slices.Equal(f.Data, tt.clamped) {
+			t.Errorf("%s: Clamp(0, 1) gives %v, want %v", tt.name, f.Data, tt.clamped)
+		}
+	}
+}
+
+// TestResample checks that Resample keeps the corner cells, interpolates
+// between them and handles empty and single-cell fields.
+func TestResample(t *testing.T) {
+	// a 2x2 field whose corners are 0, 1, 2 and 3
+	corners := &Field{Width: 2, Height: 2, Data: []float64{0, 1, 2, 3}}
+	tests := []struct {
+		name          string
+		f             *Field
+		width, height int
+		want          []float64
+	}{
+		{"empty source", New(0, 0), 2, 1, []float64{0, 0}},
+		{"empty target", corners, 0, 0, []float64{}},
+		{"single cell up", &Field{Width: 1, Height: 1, Data: []float64{5}}, 2, 2, []float64{5, 5, 5, 5}},
+		{"single cell down", corners, 1, 1, []float64{0}},
+		{"same size", corners, 2, 2, []float64{0, 1, 2, 3}},
+		{"corner aligned up", corners, 3, 3, []float64{
+			0, 0.5, 1,
+			1, 1.5, 2,
+			2, 2.5, 3,
+		}},
+		{"corner aligned down", &Field{Width: 3, Height: 1, Data: []float64{0, 7, 4}}, 2, 1, []float64{0, 4}},
+	}
+	for _, tt := range tests {
+		got := tt.f.Resample(tt.width, tt.height)
+		if got.Width != tt.width || got.Height != tt.height || !slices.Equal(got.Data, tt.want) {
+			t.Errorf("%s: Resample(%d, %d) = %dx%d %v, want %v", tt.name, tt.width, tt.height, got.Width, got.Height, got.Data, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Package imagediff compares rendered images against reference ("golden")
+// images with a perceptual tolerance, and draws diff images that show where
+// they differ.
+package imagediff
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// diffColor marks differing pixels in diff images.
+var diffColor = color.RGBA{R: 255, G: 0, B: 40, A: 255}
+
+// Result describes how two images differ.
+type Result struct {
+	// Differing is the number of pixels whose distance exceeds the tolerance.
+	Differing int
+	// Total is the number of pixels compared.
+	Total int
+	// MaxDistance is the largest perceptual distance of any pixel, in [0,1].
+	MaxDistance float64
+	// Diff shows the reference image faded to grey with the differing pixels
+	// drawn over it in red, brighter the more they differ.
+	Diff *image.RGBA
+}
+
+// Fraction returns the share of pixels that differ.
+func (r Result) Fraction() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Differing) / float64(r.Total)
+}
+
+// Distance returns the perceptual distance between two colors in [0,1], using
+// the "redmean" weighting of RGB that approximates how different the colors
+// look. Alpha is compared like a fourth channel.
+func Distance(a, b color.Color) float64 {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	r1, g1, b1 := float64(ar)/0xffff, float64(ag)/0xffff, float64(ab)/0xffff
+	r2, g2, b2 := float64(br)/0xffff, float64(bg)/0xffff, float64(bb)/0xffff
+
+	rm := (r1 + r2) / 2
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	da := (float64(aa) - float64(ba)) / 0xffff
+	// weights sum to 9 at most, plus alpha
+	d := (2+rm)*dr*dr + 4*dg*dg + (3-rm)*db*db + da*da
+	return math.Sqrt(d / 10)
+}
+
+// Compare measures how got differs from want. Pixels further apart than
+// tolerance (see Distance) count as differing. Images of different sizes
+// cannot be compared and return an error.
+func Compare(got, want image.Image, tolerance float64) (Result, error) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return Result{}, fmt.Errorf("imagediff: size %dx%d does not match reference %dx%d", gb.Dx(), gb.Dy(), wb.Dx(), wb.Dy())
+	}
+
+	res := Result{
+		Total: wb.Dx() * wb.Dy(),
+		Diff:  image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy())),
+	}
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			w := want.At(wb.Min.X+x, wb.Min.Y+y)
+			d := Distance(got.At(gb.Min.X+x, gb.Min.Y+y), w)
+			res.MaxDistance = max(res.MaxDistance, d)
+
+			if d > tolerance {
+				res.Differing++
+				// at least half bright so small differences stay visible
+				k := 0.5 + 0.5*min(d/0.25, 1)
+				res.Diff.SetRGBA(x, y, color.RGBA{
+					R: uint8(float64(diffColor.R) * k),
+					G: uint8(float64(diffColor.G) * k),
+					B: uint8(float64(diffColor.B) * k),
+					A: 255,
+				})
+				continue
+			}
+			// faded grey context
+			gray := color.GrayModel.Convert(w).(color.Gray).Y
+			v := uint8(160 + int(gray)*95/255)
+			res.Diff.SetRGBA(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"perlin_noise/analysis"
+	"perlin_noise/render"
+	"perlin_noise/world"
+)
+
+// Heatmaps that can be shown in place of the map.
+const (
+	heatmapOff          = "off"
+	heatmapRuggedness   = "ruggedness"
+	heatmapHabitability = "habitability"
+)
+
+var heatmaps = []string{heatmapOff, heatmapRuggedness, heatmapHabitability}
+
+// heatmapValues computes the named index of w, row-major, or nil for heatmapOff.
+func heatmapValues(w *world.World, name string) []float64 {
+	switch name {
+	case heatmapRuggedness:
+		return analysis.Ruggedness(w)
+	case heatmapHabitability:
+		return analysis.Habitability(w, analysis.Ruggedness(w))
+	}
+	return nil
+}
+
+// influenceWeights scales each POI's influence radius of w by the habitability
+// of its cell, from half size on barren ground to one and a half on the best,
+// standing in for the population a settlement there could grow to.
+func influenceWeights(w *world.World) []float64 {
+	habitability := analysis.Habitability(w, analysis.Ruggedness(w))
+	weights := make([]float64, len(w.POIs))
+	for i, p := range w.POIs {
+		weights[i] = 0.5 + habitability[p.Y*w.Params.Width+p.X]
+	}
+	return weights
+}
+
+// poiDesirability is the chance a POI candidate on each cell of w is kept:
+// its habitability, so settlements gather on gentle, watered lowland.
+func poiDesirability(w *world.World) []float64 {
+	return analysis.Habitability(w, analysis.Ruggedness(w))
+}
+
+// mountainLabels names the mountain ranges of w on the map and marks the
+// highest peak of each with its name and elevation.
+func mountainLabels(w *world.World) []render.Label {
+	var labels []render.Label
+	for _, r := range analysis.MountainRanges(w, analysis.MinRangeCells) {
+		// the peak goes first, so the range name makes way for its marker
+		labels = append(labels,
+			render.Label{Text: fmt.Sprintf("%s %.2f", r.PeakName, r.PeakElevation), X: r.PeakX, Y: r.PeakY, Peak: true},
+			render.Label{Text: r.Name, X: r.LabelX, Y: r.LabelY},
+		)
+	}
+	return labels
+}
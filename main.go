@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
 	"image/png"
+	"io"
+	"maps"
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,39 +23,76 @@ import (
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"perlin_noise/analysis"
+	"perlin_noise/export"
+	"perlin_noise/heightfield"
+	"perlin_noise/modules"
 	"perlin_noise/perlin"
 	"perlin_noise/poi"
+	"perlin_noise/render"
+	"perlin_noise/world"
 )
 
 const (
 	width, height = 512, 512
-)
 
-var (
-	deepWaterColor = color.RGBA{R: 25, G: 70, B: 120, A: 255}
-	waterColor     = color.RGBA{R: 50, G: 150, B: 200, A: 255}
-	shoreColor     = color.RGBA{R: 240, G: 230, B: 140, A: 255}
-	landColor      = color.RGBA{R: 80, G: 180, B: 80, A: 255}
-	mountainColor  = color.RGBA{R: 120, G: 100, B: 80, A: 255}
-	highMountainColor = color.RGBA{R: 255, G: 255, B: 255, A: 255}
-	highLandColor     = color.RGBA{R: 100, G: 150, B: 100, A: 255}
-	poiColor       = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	// mesh export settings
+	meshStep        = 2
+	meshHeightScale = 80.0
+
+	// base thickness of STL prints, in millimetres
+	printBaseThickness = 3.0
+
+	// sea level rise per flood step
+	floodStepSize = 0.01
+
+	// while a slider is dragged the map is generated at 1/dragPreviewStep of
+	// its size (128x128) and enlarged
+	dragPreviewStep = 4
+
+	// quiet period after the last change before a map is generated, so a
+	// burst of changes costs one generation
+	updateDebounce = 60 * time.Millisecond
 )
 
-func clamp01(v float64) float64 {
-	if v < 0 {
-		return 0
+// worldWarning describes a degenerate world (no land, no water, or no room for POIs)
+// together with the sliders most likely to fix it. It returns "" for a normal world.
+func worldWarning(landCells, totalCells int, poiErr error) string {
+	switch {
+	case landCells == 0:
+		return "The whole map is under water. Try lowering Sea Level, raising Continent Weight or lowering Falloff Weight."
+	case landCells == totalCells:
+		return "The map has no water. Try raising Sea Level or Falloff Weight."
+	case errors.Is(poiErr, poi.ErrNoLand):
+		return fmt.Sprintf("No land rises %.2f above sea level, the minimum for points of interest. Try lowering Sea Level.", poi.LandMargin)
+	case poiErr != nil:
+		return "Points of interest could not be placed: " + poiErr.Error()
 	}
-	if v > 1 {
-		return 1
-	}
-	return v
+	return ""
+}
+
+// update is what one map update works from. triggerUpdate reads it on the
+// GUI thread, so slider moves mid-render cannot mix settings.
+type update struct {
+	params                  world.Params
+	opts                    render.Options
+	heatmap                 string
+	kmPerPixel              float64
+	influenceByHabitability bool
+	poisByHabitability      bool
 }
 
 func main() {
+	pprofAddr := flag.String("pprof", "", "serve pprof profiles on this address, e.g. localhost:6060")
+	flag.Parse()
+	if *pprofAddr != "" {
+		startPprof(*pprofAddr)
+	}
+
 	// seed the global rand for the randomize button
 	rand.Seed(time.Now().UnixNano())
 
@@ -57,27 +102,49 @@ func main() {
 	myWindow.Resize(fyne.NewSize(1024, 768))
 
 	// Default parameters (tweak to taste)
-	var seed int64 = 12345
-	var scale float64 = 0.006
-	var octavesFloat float64 = 5.0
-	var persistence float64 = 0.5
-	var lacunarity float64 = 2.0
+	params := world.DefaultParams(width, height)
+
+	var detailIntensity float64 = 0.3
+	var aoStrength float64 = 0.5
+	var waterGlint bool = true
+	var coastalFoam bool = true
+	var highContrast bool
+
+	// seconds between worlds in watch mode
+	var watchInterval float64 = 5
 
-	var continentFreq float64 = 0.004
-	var continentOctavesFloat float64 = 3.0
-	var continentWeight float64 = 0.6
+	// POI influence overlay; radius 0 follows the POI spacing
+	var influenceRadius float64
+	var influenceFields bool
+	var influenceByHabitability bool
 
-	var falloff float64 = 1.8
-	var falloffWeight float64 = 0.6
+	// place POIs more often where habitability is high
+	var poisByHabitability bool
 
-	var seaLevel float64 = 0.45
-	var minDistance int64 = 25
+	// index drawn as a heatmap in place of the map (heatmapOff = the map)
+	heatmap := heatmapOff
+
+	// per-layer visibility, opacity and blend mode, guarded by mutex
+	layerNames := []string{render.LayerTerrain, render.LayerGrid, render.LayerInfluence, render.LayerPOIs, render.LayerLabels}
+	layerStyles := make(map[string]render.LayerStyle, len(layerNames))
+	for _, name := range layerNames {
+		layerStyles[name] = render.Options{}.Style(name)
+	}
 
-	var flowScale float64 = 0.002
-	var flowStrength float64 = 15.0
+	// sea level rise above SeaLevel in flood mode (0 = no flood)
+	var floodRise float64
+
+	// real-world scale used by the ruler
+	var kmPerPixel float64 = 2.0
+
+	var printSize float64 = 120.0
+	var printExaggeration float64 = 1.5
 
 	var mutex sync.Mutex
-	var isGenerating bool
+	// cancels the update in flight once a newer one replaces it
+	cancelUpdate := context.CancelFunc(func() {})
+	// fires the pending debounced update
+	var updateTimer *time.Timer
 
 	// Shared image (always replaced atomically)
 	img := image.NewRGBA(image.Rect(0, 0, width, height))
@@ -85,277 +152,658 @@ func main() {
 	imageCanvas.SetMinSize(fyne.NewSize(width, height))
 	imageCanvas.FillMode = canvas.ImageFillOriginal
 
+	// Heights of the last generated map, kept for mesh export
+	var heights *heightfield.Field
+	// The last generated world, kept for the HTML export
+	var current *world.World
+	// Creation log of the last generated world, shown and saved with it
+	var creationLog []string
+
 	// Labels
-	seedLabel := widget.NewLabel(fmt.Sprintf("Seed: %d", seed))
-	scaleLabel := widget.NewLabel(fmt.Sprintf("Scale: %.4f", scale))
-	octavesLabel := widget.NewLabel(fmt.Sprintf("Octaves: %.0f", octavesFloat))
-	persistenceLabel := widget.NewLabel(fmt.Sprintf("Persistence: %.2f", persistence))
-	lacunarityLabel := widget.NewLabel(fmt.Sprintf("Lacunarity: %.2f", lacunarity))
+	seedLabel := widget.NewLabel(fmt.Sprintf("Seed: %d", params.Seed))
+	scaleLabel := widget.NewLabel(fmt.Sprintf("Scale: %.4f", params.Scale))
+	octavesLabel := widget.NewLabel(fmt.Sprintf("Octaves: %d", params.Octaves))
+	persistenceLabel := widget.NewLabel(fmt.Sprintf("Persistence: %.2f", params.Persistence))
+	lacunarityLabel := widget.NewLabel(fmt.Sprintf("Lacunarity: %.2f", params.Lacunarity))
+
+	hybridOffsetLabel := widget.NewLabel(fmt.Sprintf("Hybrid Offset: %.2f", params.HybridOffset))
+	hybridGainLabel := widget.NewLabel(fmt.Sprintf("Hybrid Gain: %.2f", params.HybridGain))
 
-	continentFreqLabel := widget.NewLabel(fmt.Sprintf("Continent Freq: %.4f", continentFreq))
-	continentOctavesLabel := widget.NewLabel(fmt.Sprintf("Continent Octaves: %.0f", continentOctavesFloat))
-	continentWeightLabel := widget.NewLabel(fmt.Sprintf("Continent Weight: %.2f", continentWeight))
+	continentFreqLabel := widget.NewLabel(fmt.Sprintf("Continent Freq: %.4f", params.ContinentFreq))
+	continentOctavesLabel := widget.NewLabel(fmt.Sprintf("Continent Octaves: %d", params.ContinentOctaves))
+	continentWeightLabel := widget.NewLabel(fmt.Sprintf("Continent Weight: %.2f", params.ContinentWeight))
 
-	falloffLabel := widget.NewLabel(fmt.Sprintf("Falloff: %.2f", falloff))
-	falloffWeightLabel := widget.NewLabel(fmt.Sprintf("Falloff Weight: %.2f", falloffWeight))
+	turbulenceLabel := widget.NewLabel(fmt.Sprintf("Turbulence: %.2f", params.Turbulence))
 
-	seaLevelLabel := widget.NewLabel(fmt.Sprintf("Sea Level: %.2f", seaLevel))
-	minDistanceLabel := widget.NewLabel(fmt.Sprintf("Min. Distance: %d", minDistance))
+	falloffLabel := widget.NewLabel(fmt.Sprintf("Falloff: %.2f", params.Falloff))
+	falloffWeightLabel := widget.NewLabel(fmt.Sprintf("Falloff Weight: %.2f", params.FalloffWeight))
 
-	flowScaleLabel := widget.NewLabel(fmt.Sprintf("Flow Scale: %.4f", flowScale))
-	flowStrengthLabel := widget.NewLabel(fmt.Sprintf("Flow Strength: %.2f", flowStrength))
+	seaLevelLabel := widget.NewLabel(fmt.Sprintf("Sea Level: %.2f", params.SeaLevel))
+	minDistanceLabel := widget.NewLabel(fmt.Sprintf("Min. Distance: %d", params.MinDistance))
 
-	// updateImage (background-generation safe)
-	updateImage := func() {
-		// create a fresh out image locally to avoid mutating the shared img while UI reads it
-		out := image.NewRGBA(image.Rect(0, 0, width, height))
+	heightExponentLabel := widget.NewLabel(fmt.Sprintf("Height Exponent: %.2f", params.HeightExponent))
+	// terraceText formats the terrace count, 0 meaning no terracing
+	terraceText := func(steps int) string {
+		if steps == 0 {
+			return "Terraces: off"
+		}
+		return fmt.Sprintf("Terraces: %d", steps)
+	}
+	terraceLabel := widget.NewLabel(terraceText(params.TerraceSteps))
+	terraceSharpnessLabel := widget.NewLabel(fmt.Sprintf("Terrace Sharpness: %.2f", params.TerraceSharpness))
+	// coastSmoothingText formats the number of erosion passes, 0 meaning none
+	coastSmoothingText := func(passes int) string {
+		if passes == 0 {
+			return "Coast Smoothing: off"
+		}
+		return fmt.Sprintf("Coast Smoothing: %d passes", passes)
+	}
+	coastSmoothingLabel := widget.NewLabel(coastSmoothingText(params.CoastSmoothing))
 
-		// local perlin instance
-		p := perlin.NewPerlin(seed)
+	flowScaleLabel := widget.NewLabel(fmt.Sprintf("Flow Scale: %.4f", params.FlowScale))
+	flowStrengthLabel := widget.NewLabel(fmt.Sprintf("Flow Strength: %.2f", params.FlowStrength))
 
-		centerX := float64(width) / 2.0
-		centerY := float64(height) / 2.0
-		maxDist := math.Hypot(centerX, centerY)
+	// tilePeriodText formats the tile period, 0 meaning no tiling
+	tilePeriodText := func(period int) string {
+		if period == 0 {
+			return "Tile Period: off"
+		}
+		return fmt.Sprintf("Tile Period: %d px", period)
+	}
+	tilePeriodLabel := widget.NewLabel(tilePeriodText(params.TilePeriod))
 
-		octaves := int(octavesFloat)
-		continentOctaves := int(continentOctavesFloat)
+	detailIntensityLabel := widget.NewLabel(fmt.Sprintf("Texture Detail: %.2f", detailIntensity))
 
-		// prepare map for POIs
-		noiseMap := make(map[poi.Point]float64, width*height)
+	aoStrengthLabel := widget.NewLabel(fmt.Sprintf("Ambient Occlusion: %.2f", aoStrength))
 
-		for y := 0; y < height; y++ {
-			for x := 0; x < width; x++ {
-				// signed flow in [-1,1]
-				flowXRaw, flowYRaw := p.NoiseFlow(float64(x), float64(y), flowScale)
-				dx := flowXRaw * flowStrength
-				dy := flowYRaw * flowStrength
-
-				px := float64(x) + dx
-				py := float64(y) + dy
-
-				// local detail
-				localRaw := p.FBM2DRaw(px, py, scale, octaves, persistence, lacunarity)
-				// large-scale continent mask
-				continentRaw := p.FBM2DRaw(float64(x), float64(y), continentFreq, continentOctaves, 0.5, 2.0)
-
-				combinedRaw := localRaw*(1.0-continentWeight) + continentRaw*continentWeight
-				combined := (combinedRaw + 1.0) * 0.5
-
-				dist := math.Hypot(float64(x)-centerX, float64(y)-centerY)
-				falloffVal := math.Pow(dist/maxDist, falloff) * falloffWeight
-
-				noiseValue := clamp01(combined - falloffVal)
-
-				// store for POIs
-				noiseMap[poi.Point{X: x, Y: y}] = noiseValue
-
-				// color
-				var pixelColor color.Color
-				if noiseValue < seaLevel-0.15 {
-					pixelColor = deepWaterColor
-				} else if noiseValue < seaLevel {
-					pixelColor = waterColor
-				} else if noiseValue < seaLevel+0.04 {
-					pixelColor = shoreColor
-				} else if noiseValue < seaLevel+0.10 {
-					pixelColor = landColor
-				} else if noiseValue < seaLevel+0.20 {
-					pixelColor = highLandColor
-				} else if noiseValue < seaLevel+0.30 {
-					pixelColor = mountainColor
-				} else {
-					pixelColor = highMountainColor
-				}
-				out.Set(x, y, pixelColor)
-			}
+	watchIntervalLabel := widget.NewLabel(fmt.Sprintf("Watch Interval: %.0f s", watchInterval))
+
+	influenceRadiusText := func(r float64) string {
+		if r <= 0 {
+			return "Influence Radius: POI spacing"
+		}
+		return fmt.Sprintf("Influence Radius: %.0f px", r)
+	}
+	influenceRadiusLabel := widget.NewLabel(influenceRadiusText(influenceRadius))
+
+	floodLabel := widget.NewLabel("Flood: off")
+	floodLabel.Wrapping = fyne.TextWrapWord
+
+	creationLogLabel := widget.NewLabel("")
+	creationLogLabel.Wrapping = fyne.TextWrapWord
+
+	// shown when the current parameters are invalid or produce a degenerate world
+	warningLabel := widget.NewLabel("")
+	warningLabel.Importance = widget.WarningImportance
+	warningLabel.Wrapping = fyne.TextWrapWord
+	warningLabel.Hide()
+
+	mapScaleLabel := widget.NewLabel(fmt.Sprintf("Map Scale: %.2f km/px", kmPerPixel))
+	rulerLabel := widget.NewLabel("Ruler: off")
+
+	printSizeLabel := widget.NewLabel(fmt.Sprintf("Print Size: %.0f mm", printSize))
+	printExaggerationLabel := widget.NewLabel(fmt.Sprintf("Print Exaggeration: %.2f", printExaggeration))
+
+	// Generation events: log stage timings and show the running stage in the
+	// status label and its progress in the bar under the map
+	statusLabel := widget.NewLabel("Status: idle")
+	progressBar := widget.NewProgressBar()
+	events := &world.Events{}
+	events.Subscribe(world.LogObserver())
+	events.Subscribe(world.Observer{
+		OnStageStart: func(stage world.Stage) {
+			fyne.Do(func() {
+				statusLabel.SetText(fmt.Sprintf("Status: generating (%s)", stage))
+			})
+		},
+		OnProgress: func(stage world.Stage, done, total int) {
+			fyne.Do(func() {
+				progressBar.SetValue(float64(done) / float64(total))
+			})
+		},
+	})
+
+	// continent mask of the last update, reused while only the detail changes
+	cache := &world.Cache{}
+
+	// renderOptions collects the current render settings
+	renderOptions := func() render.Options {
+		mutex.Lock()
+		styles := maps.Clone(layerStyles)
+		mutex.Unlock()
+
+		return render.Options{
+			Styles:          styles,
+			DetailIntensity: detailIntensity,
+			AOStrength:      aoStrength,
+			WaterGlint:      waterGlint,
+			CoastalFoam:     coastalFoam,
+			HighContrast:    highContrast,
+			FloodRise:       floodRise,
+			FloodStep:       floodStepSize,
+			InfluenceRadius: influenceRadius,
+			InfluenceFields: influenceFields,
 		}
+	}
 
-		// POIs drawn onto out
-		// Each POI run needs its own source to be threadsafe
-		poiRand := rand.New(rand.NewSource(seed))
-		pois, _ := poi.PoissonDisk(minDistance, width, height, poiRand, noiseMap, seaLevel)
-		for _, pnt := range pois {
-			for i := -1; i <= 1; i++ {
-				for j := -1; j <= 1; j++ {
-					xx := pnt.X + i
-					yy := pnt.Y + j
-					if xx >= 0 && xx < width && yy >= 0 && yy < height {
-						out.Set(xx, yy, poiColor)
+	// updateImage (background-generation safe) renders u; it gives up as
+	// soon as ctx is cancelled by a newer update
+	updateImage := func(ctx context.Context, u update) {
+		// per-stage timings of this update, shown once it is ready
+		timings := &world.Timings{}
+		events := events.With(timings.Observer(), world.Observer{
+			// show a coarse preview while the full map is still generating
+			OnLayerReady: func(layer world.Layer, data any) {
+				if layer != world.LayerPreview {
+					return
+				}
+				preview := render.Preview(data.(world.Preview), u.opts, width, height)
+				fyne.Do(func() {
+					if ctx.Err() != nil {
+						return
 					}
+					imageCanvas.Image = preview
+					imageCanvas.Refresh()
+				})
+			},
+		})
+
+		// invalid parameters produce no world; say why instead
+		var genOpts []world.Option
+		if u.poisByHabitability {
+			genOpts = append(genOpts, world.WithPOIDesirability(poiDesirability))
+		}
+		w, err := world.GenerateCached(ctx, u.params, events, cache, genOpts...)
+		if ctx.Err() != nil {
+			return
+		}
+		if w == nil {
+			fyne.Do(func() {
+				warningLabel.SetText("Invalid parameters:\n" + err.Error())
+				warningLabel.Show()
+			})
+			return
+		}
+
+		opts := u.opts
+		if u.influenceByHabitability {
+			opts.InfluenceWeights = influenceWeights(w)
+		}
+		if opts.Style(render.LayerLabels).Visible {
+			opts.Labels = mountainLabels(w)
+		}
+		// a fresh image is rendered each time, so the shared img is never mutated while the UI reads it
+		out := render.Render(w, opts, events)
+		if values := heatmapValues(w, u.heatmap); values != nil {
+			out = render.Heatmap(values, width, height)
+		}
+
+		warning := worldWarning(w.LandCells(), width*height, err)
+		submerged, newlySubmerged := render.FloodedPOIs(w, opts)
+		logLines := analysis.CreationLog(w, u.kmPerPixel)
+
+		floodText := "Flood: off"
+		if opts.FloodRise > 0 {
+			floodText = fmt.Sprintf("Flood: +%.2f, %d/%d POIs submerged (%d this step)", opts.FloodRise, len(submerged), len(w.POIs), len(newlySubmerged))
+			if len(newlySubmerged) > 0 {
+				lost := make([]string, len(newlySubmerged))
+				for i, pnt := range newlySubmerged {
+					lost[i] = fmt.Sprintf("(%d, %d)", pnt.X, pnt.Y)
 				}
+				floodText += "\nLost this step: " + strings.Join(lost, ", ")
 			}
 		}
 
-		// swap into shared img under mutex
+		// swap into shared img under mutex, unless a newer update has started
 		mutex.Lock()
+		if ctx.Err() != nil {
+			mutex.Unlock()
+			return
+		}
 		img = out
+		heights = w.Heights()
+		current = w
+		creationLog = logLines
 		mutex.Unlock()
 
 		// Schedule UI update on the main GUI thread using fyne.Do
 		fyne.Do(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			statusLabel.SetText("Status: ready (" + timings.String() + ")")
+			progressBar.SetValue(1)
+			floodLabel.SetText(floodText)
+			creationLogLabel.SetText(strings.Join(logLines, "\n"))
+			if warning != "" {
+				warningLabel.SetText(warning)
+				warningLabel.Show()
+			} else {
+				warningLabel.Hide()
+			}
 			imageCanvas.Image = img
 			imageCanvas.Refresh()
 		})
 	}
 
-	// safe trigger
-	triggerUpdate := func() {
+	// restartUpdate cancels the update in flight and returns the context of
+	// the one replacing it
+	restartUpdate := func() context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
 		mutex.Lock()
-		defer mutex.Unlock()
-		if !isGenerating {
-			isGenerating = true
-			go func() {
-				updateImage()
-				mutex.Lock()
-				isGenerating = false
-				mutex.Unlock()
-			}()
+		cancelUpdate()
+		cancelUpdate = cancel
+		mutex.Unlock()
+		return ctx
+	}
+
+	// safe trigger: a change aborts the update in flight at once, and the
+	// map is generated from the settings as they are once changes pause for
+	// updateDebounce. Only the latest settings are ever rendered, so the map
+	// always ends up matching the controls.
+	triggerUpdate := func() {
+		ctx := restartUpdate()
+		if updateTimer != nil {
+			updateTimer.Stop()
 		}
+		updateTimer = time.AfterFunc(updateDebounce, func() {
+			fyne.Do(func() {
+				// a newer change or preview has taken over
+				if ctx.Err() != nil {
+					return
+				}
+				go updateImage(ctx, update{
+					params:                  params,
+					opts:                    renderOptions(),
+					heatmap:                 heatmap,
+					kmPerPixel:              kmPerPixel,
+					influenceByHabitability: influenceByHabitability,
+					poisByHabitability:      poisByHabitability,
+				})
+			})
+		})
+	}
+
+	// triggerPreview shows a quick low-resolution map while a slider is being
+	// dragged; the slider's OnChangeEnded then triggers the full update.
+	// Recipes and tiling do not scale down, so they wait for the full map.
+	triggerPreview := func() {
+		ctx := restartUpdate()
+		params := params
+		if params.Recipe != nil || params.TilePeriod > 0 {
+			return
+		}
+		// read the settings here, on the GUI thread, not in the goroutine
+		opts := renderOptions()
+		go func() {
+			small := params.Scaled((width+dragPreviewStep-1)/dragPreviewStep, (height+dragPreviewStep-1)/dragPreviewStep)
+			// no cache, so the full-size layers survive the drag
+			w, _ := world.GenerateCached(ctx, small, nil, nil)
+			if w == nil {
+				return
+			}
+			// markers would be enlarged into blobs
+			w.POIs = nil
+			preview := render.Preview(world.Preview{World: w, Step: dragPreviewStep}, opts, width, height)
+			fyne.Do(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				imageCanvas.Image = preview
+				imageCanvas.Refresh()
+			})
+		}()
 	}
 
 	// Seed slider (no automatic generation on change)
 	seedSlider := widget.NewSlider(0, 100000)
 	seedSlider.Step = 1
-	seedSlider.Value = float64(seed)
+	seedSlider.Value = float64(params.Seed)
 	seedSlider.OnChanged = func(v float64) {
-		seed = int64(v)
-		seedLabel.SetText(fmt.Sprintf("Seed: %d", seed))
+		params.Seed = int64(v)
+		seedLabel.SetText(fmt.Sprintf("Seed: %d", params.Seed))
 		// note: no triggerUpdate() here to avoid generating on every drag
 	}
 
 	// Randomize Seed button - sets a new seed and triggers a single generation
 	randomSeedBtn := widget.NewButton("Randomize Seed & Generate", func() {
-		seed = rand.Int63n(100000)
-		seedLabel.SetText(fmt.Sprintf("Seed: %d", seed))
+		params.Seed = rand.Int63n(100000)
+		seedLabel.SetText(fmt.Sprintf("Seed: %d", params.Seed))
 		// also update slider position to reflect new seed
-		seedSlider.SetValue(float64(seed))
+		seedSlider.SetValue(float64(params.Seed))
+		triggerUpdate()
+	})
+
+	// Watch mode - a full-screen screensaver of random worlds; the button is
+	// wired up once the map view exists
+	watchIntervalSlider := widget.NewSlider(2, 60)
+	watchIntervalSlider.Step = 1
+	watchIntervalSlider.Value = watchInterval
+	watchIntervalSlider.OnChanged = func(v float64) {
+		watchInterval = v
+		watchIntervalLabel.SetText(fmt.Sprintf("Watch Interval: %.0f s", watchInterval))
+	}
+	watchButton := widget.NewButton("Watch Mode", nil)
+
+	// Noise backend
+	noiseSelect := widget.NewSelect(world.NoiseBackends, func(v string) {
+		params.Noise = v
+		triggerUpdate()
+	})
+	noiseSelect.Selected = params.Noise
+
+	// Perlin gradient set
+	gradientOptions := make([]string, len(perlin.GradientSets))
+	for i, g := range perlin.GradientSets {
+		gradientOptions[i] = strconv.Itoa(int(g))
+	}
+	gradientsSelect := widget.NewSelect(gradientOptions, func(v string) {
+		n, _ := strconv.Atoi(v)
+		params.Gradients = perlin.Gradients(n)
+		triggerUpdate()
+	})
+	gradientsSelect.Selected = strconv.Itoa(int(params.Gradients))
+
+	// Terrain recipe: the sliders, or a module graph loaded from a file
+	recipeSelect := widget.NewSelect(recipeOptions(), func(v string) {
+		if v == builtInRecipe {
+			params.Recipe, params.RecipeDir = nil, ""
+			triggerUpdate()
+			return
+		}
+		recipe, err := loadRecipe(v)
+		if err != nil {
+			fmt.Println("recipe load error:", err)
+			return
+		}
+		// its masks are found next to it
+		params.Recipe, params.RecipeDir = recipe, filepath.Dir(v)
+		triggerUpdate()
+	})
+	recipeSelect.Selected = builtInRecipe
+
+	saveRecipeButton := widget.NewButton("Save Recipe", func() {
+		// the loaded recipe, or the sliders' terrain written out as one
+		recipe := params.Recipe
+		if recipe == nil {
+			recipe = world.Recipe(params)
+		}
+		filename := fmt.Sprintf("world_%d%s", time.Now().Unix(), modules.RecipeExt)
+		if err := saveRecipe(filename, recipe); err != nil {
+			fmt.Println("recipe save error:", err)
+			return
+		}
+		recipeSelect.SetOptions(recipeOptions())
+	})
+
+	// Terrain style
+	styleSelect := widget.NewSelect(world.TerrainStyles, func(v string) {
+		params.TerrainStyle = v
+		triggerUpdate()
+	})
+	styleSelect.Selected = params.TerrainStyle
+
+	// Continent mask noise; the first option follows the terrain noise
+	const sameNoise = "same as terrain"
+	continentNoiseSelect := widget.NewSelect(append([]string{sameNoise}, world.ContinentNoiseBackends...), func(v string) {
+		if v == sameNoise {
+			v = ""
+		}
+		params.ContinentNoise = v
 		triggerUpdate()
 	})
+	continentNoiseSelect.Selected = sameNoise
+
+	continentStyleSelect := widget.NewSelect(world.TerrainStyles, func(v string) {
+		params.ContinentStyle = v
+		triggerUpdate()
+	})
+	continentStyleSelect.Selected = params.ContinentStyle
+
+	// Hybrid multifractal sliders, used by the "hybrid" style
+	hybridOffsetSlider := widget.NewSlider(0.0, 1.5)
+	hybridOffsetSlider.Step = 0.05
+	hybridOffsetSlider.Value = params.HybridOffset
+	hybridOffsetSlider.OnChanged = func(v float64) {
+		params.HybridOffset = v
+		hybridOffsetLabel.SetText(fmt.Sprintf("Hybrid Offset: %.2f", params.HybridOffset))
+		triggerPreview()
+	}
+
+	hybridGainSlider := widget.NewSlider(0.25, 4.0)
+	hybridGainSlider.Step = 0.05
+	hybridGainSlider.Value = params.HybridGain
+	hybridGainSlider.OnChanged = func(v float64) {
+		params.HybridGain = v
+		hybridGainLabel.SetText(fmt.Sprintf("Hybrid Gain: %.2f", params.HybridGain))
+		triggerPreview()
+	}
 
 	// Scale slider
 	scaleSlider := widget.NewSlider(0.001, 0.02)
 	scaleSlider.Step = 0.0005
-	scaleSlider.Value = scale
+	scaleSlider.Value = params.Scale
 	scaleSlider.OnChanged = func(v float64) {
-		scale = v
-		scaleLabel.SetText(fmt.Sprintf("Scale: %.4f", scale))
-		triggerUpdate()
+		params.Scale = v
+		scaleLabel.SetText(fmt.Sprintf("Scale: %.4f", params.Scale))
+		triggerPreview()
 	}
 
 	// Octaves slider
-	octavesSlider := widget.NewSlider(1, 8)
+	octavesSlider := widget.NewSlider(1, 12)
 	octavesSlider.Step = 1
-	octavesSlider.Value = octavesFloat
+	octavesSlider.Value = float64(params.Octaves)
 	octavesSlider.OnChanged = func(v float64) {
-		octavesFloat = v
-		octavesLabel.SetText(fmt.Sprintf("Octaves: %.0f", octavesFloat))
-		triggerUpdate()
+		params.Octaves = int(v)
+		octavesLabel.SetText(fmt.Sprintf("Octaves: %d", params.Octaves))
+		triggerPreview()
 	}
 
 	// Persistence slider
 	persistenceSlider := widget.NewSlider(0.1, 0.9)
 	persistenceSlider.Step = 0.01
-	persistenceSlider.Value = persistence
+	persistenceSlider.Value = params.Persistence
 	persistenceSlider.OnChanged = func(v float64) {
-		persistence = v
-		persistenceLabel.SetText(fmt.Sprintf("Persistence: %.2f", persistence))
-		triggerUpdate()
+		params.Persistence = v
+		persistenceLabel.SetText(fmt.Sprintf("Persistence: %.2f", params.Persistence))
+		triggerPreview()
 	}
 
 	// Lacunarity slider
 	lacunaritySlider := widget.NewSlider(1.5, 3.0)
 	lacunaritySlider.Step = 0.05
-	lacunaritySlider.Value = lacunarity
+	lacunaritySlider.Value = params.Lacunarity
 	lacunaritySlider.OnChanged = func(v float64) {
-		lacunarity = v
-		lacunarityLabel.SetText(fmt.Sprintf("Lacunarity: %.2f", lacunarity))
-		triggerUpdate()
+		params.Lacunarity = v
+		lacunarityLabel.SetText(fmt.Sprintf("Lacunarity: %.2f", params.Lacunarity))
+		triggerPreview()
 	}
 
 	// Continent sliders
 	continentFreqSlider := widget.NewSlider(0.0005, 0.02)
 	continentFreqSlider.Step = 0.0005
-	continentFreqSlider.Value = continentFreq
+	continentFreqSlider.Value = params.ContinentFreq
 	continentFreqSlider.OnChanged = func(v float64) {
-		continentFreq = v
-		continentFreqLabel.SetText(fmt.Sprintf("Continent Freq: %.4f", continentFreq))
-		triggerUpdate()
+		params.ContinentFreq = v
+		continentFreqLabel.SetText(fmt.Sprintf("Continent Freq: %.4f", params.ContinentFreq))
+		triggerPreview()
 	}
 
 	continentOctavesSlider := widget.NewSlider(1, 6)
 	continentOctavesSlider.Step = 1
-	continentOctavesSlider.Value = continentOctavesFloat
+	continentOctavesSlider.Value = float64(params.ContinentOctaves)
 	continentOctavesSlider.OnChanged = func(v float64) {
-		continentOctavesFloat = v
-		continentOctavesLabel.SetText(fmt.Sprintf("Continent Octaves: %.0f", continentOctavesFloat))
-		triggerUpdate()
+		params.ContinentOctaves = int(v)
+		continentOctavesLabel.SetText(fmt.Sprintf("Continent Octaves: %d", params.ContinentOctaves))
+		triggerPreview()
 	}
 
 	continentWeightSlider := widget.NewSlider(0.0, 1.0)
 	continentWeightSlider.Step = 0.01
-	continentWeightSlider.Value = continentWeight
+	continentWeightSlider.Value = params.ContinentWeight
 	continentWeightSlider.OnChanged = func(v float64) {
-		continentWeight = v
-		continentWeightLabel.SetText(fmt.Sprintf("Continent Weight: %.2f", continentWeight))
-		triggerUpdate()
+		params.ContinentWeight = v
+		continentWeightLabel.SetText(fmt.Sprintf("Continent Weight: %.2f", params.ContinentWeight))
+		triggerPreview()
+	}
+
+	// Turbulence slider
+	turbulenceSlider := widget.NewSlider(0.0, 0.5)
+	turbulenceSlider.Step = 0.01
+	turbulenceSlider.Value = params.Turbulence
+	turbulenceSlider.OnChanged = func(v float64) {
+		params.Turbulence = v
+		turbulenceLabel.SetText(fmt.Sprintf("Turbulence: %.2f", params.Turbulence))
+		triggerPreview()
 	}
 
 	// Falloff sliders
 	falloffSlider := widget.NewSlider(0.5, 4.0)
 	falloffSlider.Step = 0.05
-	falloffSlider.Value = falloff
+	falloffSlider.Value = params.Falloff
 	falloffSlider.OnChanged = func(v float64) {
-		falloff = v
-		falloffLabel.SetText(fmt.Sprintf("Falloff: %.2f", falloff))
-		triggerUpdate()
+		params.Falloff = v
+		falloffLabel.SetText(fmt.Sprintf("Falloff: %.2f", params.Falloff))
+		triggerPreview()
 	}
 
 	falloffWeightSlider := widget.NewSlider(0.0, 1.0)
 	falloffWeightSlider.Step = 0.01
-	falloffWeightSlider.Value = falloffWeight
+	falloffWeightSlider.Value = params.FalloffWeight
 	falloffWeightSlider.OnChanged = func(v float64) {
-		falloffWeight = v
-		falloffWeightLabel.SetText(fmt.Sprintf("Falloff Weight: %.2f", falloffWeight))
-		triggerUpdate()
+		params.FalloffWeight = v
+		falloffWeightLabel.SetText(fmt.Sprintf("Falloff Weight: %.2f", params.FalloffWeight))
+		triggerPreview()
 	}
 
 	// Sea level
 	seaLevelSlider := widget.NewSlider(0.0, 1.0)
 	seaLevelSlider.Step = 0.01
-	seaLevelSlider.Value = seaLevel
+	seaLevelSlider.Value = params.SeaLevel
 	seaLevelSlider.OnChanged = func(v float64) {
-		seaLevel = v
-		seaLevelLabel.SetText(fmt.Sprintf("Sea Level: %.2f", seaLevel))
+		params.SeaLevel = v
+		seaLevelLabel.SetText(fmt.Sprintf("Sea Level: %.2f", params.SeaLevel))
+		triggerPreview()
+	}
+
+	// Land height remapping
+	heightCurveSelect := widget.NewSelect(world.HeightCurves, func(v string) {
+		params.HeightCurve = v
 		triggerUpdate()
+	})
+	heightCurveSelect.Selected = params.HeightCurve
+
+	heightExponentSlider := widget.NewSlider(0.3, 3.0)
+	heightExponentSlider.Step = 0.05
+	heightExponentSlider.Value = params.HeightExponent
+	heightExponentSlider.OnChanged = func(v float64) {
+		params.HeightExponent = v
+		heightExponentLabel.SetText(fmt.Sprintf("Height Exponent: %.2f", params.HeightExponent))
+		triggerPreview()
+	}
+
+	terraceSlider := widget.NewSlider(0, 16)
+	terraceSlider.Step = 1
+	terraceSlider.Value = float64(params.TerraceSteps)
+	terraceSlider.OnChanged = func(v float64) {
+		params.TerraceSteps = int(v)
+		terraceLabel.SetText(terraceText(params.TerraceSteps))
+		triggerPreview()
+	}
+
+	terraceSharpnessSlider := widget.NewSlider(0.0, 1.0)
+	terraceSharpnessSlider.Step = 0.05
+	terraceSharpnessSlider.Value = params.TerraceSharpness
+	terraceSharpnessSlider.OnChanged = func(v float64) {
+		params.TerraceSharpness = v
+		terraceSharpnessLabel.SetText(fmt.Sprintf("Terrace Sharpness: %.2f", params.TerraceSharpness))
+		triggerPreview()
+	}
+
+	coastSmoothingSlider := widget.NewSlider(0, 10)
+	coastSmoothingSlider.Step = 1
+	coastSmoothingSlider.Value = float64(params.CoastSmoothing)
+	coastSmoothingSlider.OnChanged = func(v float64) {
+		params.CoastSmoothing = int(v)
+		coastSmoothingLabel.SetText(coastSmoothingText(params.CoastSmoothing))
+		triggerPreview()
 	}
 
 	// Min distance for POIs
 	minDistanceSlider := widget.NewSlider(1, 50)
 	minDistanceSlider.Step = 1
-	minDistanceSlider.Value = float64(minDistance)
+	minDistanceSlider.Value = float64(params.MinDistance)
 	minDistanceSlider.OnChanged = func(v float64) {
-		minDistance = int64(v)
-		minDistanceLabel.SetText(fmt.Sprintf("Min. Distance: %d", minDistance))
-		triggerUpdate()
+		params.MinDistance = int64(v)
+		minDistanceLabel.SetText(fmt.Sprintf("Min. Distance: %d", params.MinDistance))
+		triggerPreview()
 	}
+	poisByHabitabilityCheck := widget.NewCheck("Place POIs by Habitability", func(on bool) {
+		poisByHabitability = on
+		triggerUpdate()
+	})
+	poisByHabitabilityCheck.Checked = poisByHabitability
 
 	// Flow sliders
 	flowScaleSlider := widget.NewSlider(0.0, 0.02)
 	flowScaleSlider.Step = 0.0005
-	flowScaleSlider.Value = flowScale
+	flowScaleSlider.Value = params.FlowScale
 	flowScaleSlider.OnChanged = func(v float64) {
-		flowScale = v
-		flowScaleLabel.SetText(fmt.Sprintf("Flow Scale: %.4f", flowScale))
-		triggerUpdate()
+		params.FlowScale = v
+		flowScaleLabel.SetText(fmt.Sprintf("Flow Scale: %.4f", params.FlowScale))
+		triggerPreview()
 	}
 
 	flowStrengthSlider := widget.NewSlider(0, 60)
 	flowStrengthSlider.Step = 1
-	flowStrengthSlider.Value = flowStrength
+	flowStrengthSlider.Value = params.FlowStrength
 	flowStrengthSlider.OnChanged = func(v float64) {
-		flowStrength = v
-		flowStrengthLabel.SetText(fmt.Sprintf("Flow Strength: %.2f", flowStrength))
+		params.FlowStrength = v
+		flowStrengthLabel.SetText(fmt.Sprintf("Flow Strength: %.2f", params.FlowStrength))
+		triggerPreview()
+	}
+
+	// Octave toggles: skip the octaves too faint to see, and rotate and offset
+	// each octave so their lattices do not line up
+	adaptiveOctavesCheck := widget.NewCheck("Adaptive Octaves", func(on bool) {
+		params.AdaptiveOctaves = on
+		triggerUpdate()
+	})
+	adaptiveOctavesCheck.Checked = params.AdaptiveOctaves
+
+	decorrelateOctavesCheck := widget.NewCheck("Decorrelate Octaves", func(on bool) {
+		params.DecorrelateOctaves = on
 		triggerUpdate()
+	})
+	decorrelateOctavesCheck.Checked = params.DecorrelateOctaves
+
+	// Curl flow toggle
+	curlFlowCheck := widget.NewCheck("Curl Flow", func(on bool) {
+		params.CurlFlow = on
+		triggerUpdate()
+	})
+	curlFlowCheck.Checked = params.CurlFlow
+
+	// Tile period slider
+	tilePeriodSlider := widget.NewSlider(0, float64(width))
+	tilePeriodSlider.Step = 64
+	tilePeriodSlider.Value = float64(params.TilePeriod)
+	tilePeriodSlider.OnChanged = func(v float64) {
+		params.TilePeriod = int(v)
+		tilePeriodLabel.SetText(tilePeriodText(params.TilePeriod))
+		triggerPreview()
+	}
+
+	// the generation sliders preview while they move and generate the full
+	// map when they are released (or changed by a tap or key, which ends the
+	// change at once)
+	for _, s := range []*widget.Slider{
+		hybridOffsetSlider, hybridGainSlider, scaleSlider, octavesSlider, persistenceSlider, lacunaritySlider,
+		continentFreqSlider, continentOctavesSlider, continentWeightSlider, turbulenceSlider,
+		falloffSlider, falloffWeightSlider, seaLevelSlider, heightExponentSlider, terraceSlider, terraceSharpnessSlider,
+		coastSmoothingSlider, minDistanceSlider, flowScaleSlider, flowStrengthSlider, tilePeriodSlider,
+	} {
+		s.OnChangeEnded = func(float64) { triggerUpdate() }
 	}
 
 	// Save button (capture image under mutex first)
@@ -377,35 +825,439 @@ func main() {
 		}
 	})
 
+	// Save Planet button: the current settings as a seamless 2:1 equirectangular planet map
+	savePlanetButton := widget.NewButton("Save Planet PNG", func() {
+		planetParams := params
+		planetParams.Planet = true
+		planetParams.Width, planetParams.Height = 2*height, height
+		opts := renderOptions()
+
+		go func() {
+			w, err := world.Generate(planetParams, nil)
+			if w == nil {
+				fmt.Println("planet error:", err)
+				return
+			}
+
+			tempFilename := fmt.Sprintf("planet_%d.png", time.Now().Unix())
+			f, err := os.Create(tempFilename)
+			if err != nil {
+				fmt.Println("planet create error:", err)
+				return
+			}
+			defer f.Close()
+
+			if err := png.Encode(f, render.Render(w, opts, nil)); err != nil {
+				fmt.Println("png encode error:", err)
+			}
+		}()
+	})
+
+	// exportMesh writes a mesh file named after kind (its extension) in the
+	// background, as building it takes a while on large maps, and reports
+	// the result in the status label. write must only use values read on
+	// the GUI thread beforehand.
+	exportMesh := func(kind string, write func(w io.Writer) error) {
+		go func() {
+			filename := fmt.Sprintf("world_%d.%s", time.Now().Unix(), kind)
+			status := "Status: saved " + filename
+			f, err := os.Create(filename)
+			if err != nil {
+				fmt.Println(kind+" create error:", err)
+				status = fmt.Sprintf("Status: %s export failed (%v)", kind, err)
+			} else {
+				err = write(f)
+				if closeErr := f.Close(); err == nil {
+					err = closeErr
+				}
+				if err != nil {
+					fmt.Println(kind+" export error:", err)
+					// a half-written mesh is of no use
+					os.Remove(filename)
+					status = fmt.Sprintf("Status: %s export failed (%v)", kind, err)
+				}
+			}
+			fyne.Do(func() {
+				statusLabel.SetText(status)
+			})
+		}()
+	}
+
+	// Export GLB button (terrain mesh with the current image as texture)
+	exportGLBButton := widget.NewButton("Export GLB", func() {
+		mutex.Lock()
+		toExport := img
+		exportHeights := heights
+		mutex.Unlock()
+		if exportHeights == nil {
+			return
+		}
+
+		exportMesh("glb", func(w io.Writer) error {
+			return export.ExportGLB(w, exportHeights, width, height, toExport, meshStep, meshHeightScale)
+		})
+	})
+
+	// Export HTML button (interactive page with pan/zoom and POI popups)
+	exportHTMLButton := widget.NewButton("Export HTML", func() {
+		mutex.Lock()
+		toExport := img
+		w := current
+		mutex.Unlock()
+		if w == nil {
+			return
+		}
+
+		tempFilename := fmt.Sprintf("world_%d.html", time.Now().Unix())
+		f, err := os.Create(tempFilename)
+		if err != nil {
+			fmt.Println("html create error:", err)
+			return
+		}
+		defer f.Close()
+
+		title := fmt.Sprintf("World %d", w.Params.Seed)
+		if err := export.ExportHTML(f, title, toExport, w.POIs, w.Heights(), w.Params.SeaLevel, kmPerPixel); err != nil {
+			fmt.Println("html export error:", err)
+		}
+	})
+
+	// Texture detail slider
+	detailIntensitySlider := widget.NewSlider(0.0, 1.0)
+	detailIntensitySlider.Step = 0.01
+	detailIntensitySlider.Value = detailIntensity
+	detailIntensitySlider.OnChanged = func(v float64) {
+		detailIntensity = v
+		detailIntensityLabel.SetText(fmt.Sprintf("Texture Detail: %.2f", detailIntensity))
+		triggerUpdate()
+	}
+
+	// Ambient occlusion slider
+	aoStrengthSlider := widget.NewSlider(0.0, 1.0)
+	aoStrengthSlider.Step = 0.01
+	aoStrengthSlider.Value = aoStrength
+	aoStrengthSlider.OnChanged = func(v float64) {
+		aoStrength = v
+		aoStrengthLabel.SetText(fmt.Sprintf("Ambient Occlusion: %.2f", aoStrength))
+		triggerUpdate()
+	}
+
+	// Creation log, saved as plain text next to the other exports
+	saveLogButton := widget.NewButton("Save Creation Log", func() {
+		mutex.Lock()
+		lines := creationLog
+		mutex.Unlock()
+		if lines == nil {
+			return
+		}
+
+		tempFilename := fmt.Sprintf("world_%d.txt", time.Now().Unix())
+		if err := os.WriteFile(tempFilename, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+			fmt.Println("log save error:", err)
+		}
+	})
+
+	// Terrain indices, viewed as heatmaps and exported as CSV
+	heatmapSelect := widget.NewSelect(heatmaps, func(v string) {
+		heatmap = v
+		triggerUpdate()
+	})
+	heatmapSelect.Selected = heatmap
+
+	exportIndicesButton := widget.NewButton("Export Indices CSV", func() {
+		mutex.Lock()
+		w := current
+		mutex.Unlock()
+		if w == nil {
+			return
+		}
+
+		tempFilename := fmt.Sprintf("world_%d.csv", time.Now().Unix())
+		f, err := os.Create(tempFilename)
+		if err != nil {
+			fmt.Println("csv create error:", err)
+			return
+		}
+		defer f.Close()
+
+		elevation := make([]float64, width*height)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				elevation[y*width+x] = w.ElevationAt(x, y)
+			}
+		}
+		tri := analysis.Ruggedness(w)
+		names := []string{"elevation", "ruggedness", "habitability"}
+		if err := export.ExportGridCSV(f, width, height, names, elevation, tri, analysis.Habitability(w, tri)); err != nil {
+			fmt.Println("csv export error:", err)
+		}
+	})
+
+	// Water rendering toggles
+	waterGlintCheck := widget.NewCheck("Water Glint", func(on bool) {
+		waterGlint = on
+		triggerUpdate()
+	})
+	waterGlintCheck.Checked = waterGlint
+	coastalFoamCheck := widget.NewCheck("Coastal Foam", func(on bool) {
+		coastalFoam = on
+		triggerUpdate()
+	})
+	coastalFoamCheck.Checked = coastalFoam
+	highContrastCheck := widget.NewCheck("High-Contrast Colors", func(on bool) {
+		highContrast = on
+		triggerUpdate()
+	})
+	highContrastCheck.Checked = highContrast
+
+	// Layer controls: visibility, blend mode and opacity of each composited layer
+	var layerControls []fyne.CanvasObject
+	for _, name := range layerNames {
+		style := layerStyles[name]
+		setStyle := func(update func(s *render.LayerStyle)) {
+			mutex.Lock()
+			s := layerStyles[name]
+			update(&s)
+			layerStyles[name] = s
+			mutex.Unlock()
+			triggerUpdate()
+		}
+
+		visibleCheck := widget.NewCheck("Layer: "+name, func(on bool) {
+			setStyle(func(s *render.LayerStyle) { s.Visible = on })
+		})
+		visibleCheck.Checked = style.Visible
+
+		var modeNames []string
+		for _, m := range render.BlendModes {
+			modeNames = append(modeNames, m.String())
+		}
+		modeSelect := widget.NewSelect(modeNames, func(selected string) {
+			for _, m := range render.BlendModes {
+				if m.String() == selected {
+					setStyle(func(s *render.LayerStyle) { s.Mode = m })
+				}
+			}
+		})
+		modeSelect.Selected = style.Mode.String()
+
+		opacitySlider := widget.NewSlider(0.0, 1.0)
+		opacitySlider.Step = 0.05
+		opacitySlider.Value = style.Opacity
+		opacitySlider.OnChanged = func(v float64) {
+			setStyle(func(s *render.LayerStyle) { s.Opacity = v })
+		}
+
+		layerControls = append(layerControls, container.NewHBox(visibleCheck, modeSelect), opacitySlider)
+	}
+
+	// POI influence overlay, shown with the "influence" layer
+	influenceRadiusSlider := widget.NewSlider(0, 120)
+	influenceRadiusSlider.Step = 1
+	influenceRadiusSlider.Value = influenceRadius
+	influenceRadiusSlider.OnChanged = func(v float64) {
+		influenceRadius = v
+		influenceRadiusLabel.SetText(influenceRadiusText(influenceRadius))
+		triggerUpdate()
+	}
+	influenceFieldsCheck := widget.NewCheck("Blended Influence Fields", func(on bool) {
+		influenceFields = on
+		triggerUpdate()
+	})
+	influenceFieldsCheck.Checked = influenceFields
+	influenceByHabitabilityCheck := widget.NewCheck("Scale Influence by Habitability", func(on bool) {
+		influenceByHabitability = on
+		triggerUpdate()
+	})
+	influenceByHabitabilityCheck.Checked = influenceByHabitability
+
+	// Flood buttons - step the sea level up from its current setting
+	floodStepBtn := widget.NewButton("Raise Sea Level (Flood Step)", func() {
+		floodRise += floodStepSize
+		triggerUpdate()
+	})
+	floodResetBtn := widget.NewButton("Reset Flood", func() {
+		floodRise = 0
+		triggerUpdate()
+	})
+
+	// Ruler - click two points on the map to measure the distance between them
+	var rulerOn bool
+	var rulerPoints []fyne.Position
+	rulerLine := canvas.NewLine(color.RGBA{R: 255, G: 255, B: 0, A: 255})
+	rulerLine.StrokeWidth = 2
+	rulerLine.Hide()
+
+	var mapTaps *tapLayer
+	mapTaps = newTapLayer(func(pos fyne.Position) {
+		if !rulerOn {
+			return
+		}
+		if len(rulerPoints) == 2 {
+			rulerPoints = nil
+			rulerLine.Hide()
+		}
+		rulerPoints = append(rulerPoints, pos)
+		if len(rulerPoints) == 1 {
+			rulerLabel.SetText("Ruler: click the second point")
+			return
+		}
+
+		rulerLine.Position1 = rulerPoints[0]
+		rulerLine.Position2 = rulerPoints[1]
+		rulerLine.Show()
+		rulerLine.Refresh()
+
+		// canvas units -> image pixels
+		pxPerUnit := float64(width) / float64(mapTaps.Size().Width)
+		dx := float64(rulerPoints[1].X-rulerPoints[0].X) * pxPerUnit
+		dy := float64(rulerPoints[1].Y-rulerPoints[0].Y) * pxPerUnit
+		dist := math.Hypot(dx, dy)
+		rulerLabel.SetText(fmt.Sprintf("Distance: %.1f px (%.1f km)", dist, dist*kmPerPixel))
+	})
+
+	rulerCheck := widget.NewCheck("Ruler", func(on bool) {
+		rulerOn = on
+		rulerPoints = nil
+		rulerLine.Hide()
+		if on {
+			rulerLabel.SetText("Ruler: click the first point")
+		} else {
+			rulerLabel.SetText("Ruler: off")
+		}
+	})
+
+	mapScaleSlider := widget.NewSlider(0.1, 20)
+	mapScaleSlider.Step = 0.1
+	mapScaleSlider.Value = kmPerPixel
+	mapScaleSlider.OnChanged = func(v float64) {
+		kmPerPixel = v
+		mapScaleLabel.SetText(fmt.Sprintf("Map Scale: %.2f km/px", kmPerPixel))
+	}
+
+	// 3D print sliders (only used by the STL export, no regeneration)
+	printSizeSlider := widget.NewSlider(50, 300)
+	printSizeSlider.Step = 5
+	printSizeSlider.Value = printSize
+	printSizeSlider.OnChanged = func(v float64) {
+		printSize = v
+		printSizeLabel.SetText(fmt.Sprintf("Print Size: %.0f mm", printSize))
+	}
+
+	printExaggerationSlider := widget.NewSlider(0.5, 5.0)
+	printExaggerationSlider.Step = 0.1
+	printExaggerationSlider.Value = printExaggeration
+	printExaggerationSlider.OnChanged = func(v float64) {
+		printExaggeration = v
+		printExaggerationLabel.SetText(fmt.Sprintf("Print Exaggeration: %.2f", printExaggeration))
+	}
+
+	// Export STL button (watertight solid for 3D printing)
+	exportSTLButton := widget.NewButton("Export STL", func() {
+		mutex.Lock()
+		exportHeights := heights
+		mutex.Unlock()
+		if exportHeights == nil {
+			return
+		}
+
+		// the print settings are read here, on the GUI thread
+		size, exaggeration := printSize, printExaggeration
+		exportMesh("stl", func(w io.Writer) error {
+			return export.ExportSTL(w, exportHeights, width, height, meshStep, size, printBaseThickness, meshHeightScale, exaggeration)
+		})
+	})
+
 	controls := container.NewVBox(
 		widget.NewLabel("Use the sliders below to adjust the world."),
+		statusLabel,
+		warningLabel,
+		widget.NewLabel("Creation Log"), creationLogLabel, saveLogButton,
 		seedLabel, seedSlider, randomSeedBtn,
+		watchIntervalLabel, watchIntervalSlider, watchButton,
+		widget.NewLabel("Terrain Recipe"), recipeSelect, saveRecipeButton,
+		widget.NewLabel("Noise"), noiseSelect,
+		widget.NewLabel("Gradients"), gradientsSelect,
+		widget.NewLabel("Terrain Style"), styleSelect,
+		hybridOffsetLabel, hybridOffsetSlider,
+		hybridGainLabel, hybridGainSlider,
 		scaleLabel, scaleSlider,
-		octavesLabel, octavesSlider,
+		octavesLabel, octavesSlider, adaptiveOctavesCheck, decorrelateOctavesCheck,
 		persistenceLabel, persistenceSlider,
 		lacunarityLabel, lacunaritySlider,
+		widget.NewLabel("Continent Noise"), continentNoiseSelect,
+		widget.NewLabel("Continent Style"), continentStyleSelect,
 		continentFreqLabel, continentFreqSlider,
 		continentOctavesLabel, continentOctavesSlider,
 		continentWeightLabel, continentWeightSlider,
+		turbulenceLabel, turbulenceSlider,
 		falloffLabel, falloffSlider,
 		falloffWeightLabel, falloffWeightSlider,
 		seaLevelLabel, seaLevelSlider,
+		widget.NewLabel("Height Curve"), heightCurveSelect,
+		heightExponentLabel, heightExponentSlider,
+		terraceLabel, terraceSlider,
+		terraceSharpnessLabel, terraceSharpnessSlider,
+		coastSmoothingLabel, coastSmoothingSlider,
 		minDistanceLabel, minDistanceSlider,
+		poisByHabitabilityCheck,
 		flowScaleLabel, flowScaleSlider,
 		flowStrengthLabel, flowStrengthSlider,
+		curlFlowCheck,
+		tilePeriodLabel, tilePeriodSlider,
+		detailIntensityLabel, detailIntensitySlider,
+		aoStrengthLabel, aoStrengthSlider,
+		waterGlintCheck, coastalFoamCheck, highContrastCheck,
+		container.NewVBox(layerControls...),
+		influenceRadiusLabel, influenceRadiusSlider,
+		influenceFieldsCheck, influenceByHabitabilityCheck,
+		floodLabel, floodStepBtn, floodResetBtn,
+		mapScaleLabel, mapScaleSlider,
+		rulerCheck, rulerLabel,
 		saveButton,
+		savePlanetButton,
+		exportGLBButton,
+		exportHTMLButton,
+		widget.NewLabel("Heatmap"), heatmapSelect,
+		exportIndicesButton,
+		printSizeLabel, printSizeSlider,
+		printExaggerationLabel, printExaggerationSlider,
+		exportSTLButton,
 	)
 
 	scrollableControls := container.NewScroll(controls)
 
-	split := container.NewHSplit(
+	// image, ruler overlay and tap catcher share the image's exact size
+	mapView := container.NewCenter(container.NewStack(
 		imageCanvas,
+		container.NewWithoutLayout(rulerLine),
+		mapTaps,
+	))
+
+	split := container.NewHSplit(
+		container.NewBorder(nil, progressBar, nil, nil, mapView),
 		scrollableControls,
 	)
 	split.Offset = 0.75 // Adjust the initial split ratio
 
+	watchButton.OnTapped = func() {
+		interval := time.Duration(watchInterval * float64(time.Second))
+		startWatch(myWindow, mapView, interval, randomSeedBtn.OnTapped, saveButton.OnTapped)
+	}
+
 	myWindow.SetContent(split)
 
+	// Keyboard shortcuts for the main actions; Tab moves between the controls
+	// and the arrow keys adjust a focused slider
+	shortcut := func(key fyne.KeyName, action func()) {
+		myWindow.Canvas().AddShortcut(&desktop.CustomShortcut{KeyName: key, Modifier: fyne.KeyModifierShortcutDefault}, func(fyne.Shortcut) {
+			action()
+		})
+	}
+	shortcut(fyne.KeyG, triggerUpdate)
+	shortcut(fyne.KeyR, randomSeedBtn.OnTapped)
+	shortcut(fyne.KeyS, saveButton.OnTapped)
+
 	// initial render
 	triggerUpdate()
 	myWindow.ShowAndRun()
@@ -0,0 +1,103 @@
+package modules
+
+// Add outputs the sum of its sources.
+type Add struct {
+	Sources []Module
+}
+
+// Sample returns the sum of every source.
+func (a Add) Sample(x, y float64) float64 {
+	total := 0.0
+	for _, s := range a.Sources {
+		total += s.Sample(x, y)
+	}
+	return total
+}
+
+// Multiply outputs the product of its sources.
+type Multiply struct {
+	Sources []Module
+}
+
+// Sample returns the product of every source.
+func (m Multiply) Sample(x, y float64) float64 {
+	total := 1.0
+	for _, s := range m.Sources {
+		total *= s.Sample(x, y)
+	}
+	return total
+}
+
+// Min outputs the smallest of its sources, which must not be empty.
+type Min struct {
+	Sources []Module
+}
+
+// Sample returns the minimum over every source.
+func (m Min) Sample(x, y float64) float64 {
+	v := m.Sources[0].Sample(x, y)
+	for _, s := range m.Sources[1:] {
+		v = min(v, s.Sample(x, y))
+	}
+	return v
+}
+
+// Max outputs the largest of its sources, which must not be empty.
+type Max struct {
+	Sources []Module
+}
+
+// Sample returns the maximum over every source.
+func (m Max) Sample(x, y float64) float64 {
+	v := m.Sources[0].Sample(x, y)
+	for _, s := range m.Sources[1:] {
+		v = max(v, s.Sample(x, y))
+	}
+	return v
+}
+
+// Blend mixes A and B linearly: Control 0 gives A and 1 gives B.
+type Blend struct {
+	A, B    Module
+	Control Module
+}
+
+// Sample returns A + (B - A) * Control.
+func (b Blend) Sample(x, y float64) float64 {
+	t := b.Control.Sample(x, y)
+	a := b.A.Sample(x, y)
+	return a + (b.B.Sample(x, y)-a)*t
+}
+
+// Select outputs A where Control is below Threshold and B above it, fading
+// between them over Falloff on either side of the threshold.
+type Select struct {
+	A, B      Module
+	Control   Module
+	Threshold float64
+	Falloff   float64
+}
+
+// Sample returns A, B or a smooth mix of the two depending on Control.
+func (s Select) Sample(x, y float64) float64 {
+	c := s.Control.Sample(x, y)
+	if s.Falloff <= 0 {
+		if c < s.Threshold {
+			return s.A.Sample(x, y)
+		}
+		return s.B.Sample(x, y)
+	}
+
+	lo, hi := s.Threshold-s.Falloff, s.Threshold+s.Falloff
+	if c <= lo {
+		return s.A.Sample(x, y)
+	}
+	if c >= hi {
+		return s.B.Sample(x, y)
+	}
+	// smoothstep across the falloff band
+	t := (c - lo) / (hi - lo)
+	t = t * t * (3 - 2*t)
+	a := s.A.Sample(x, y)
+	return a + (s.B.Sample(x, y)-a)*t
+}
@@ -0,0 +1,60 @@
+package modules
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Mask is a source read from a grayscale image stretched over a width x
+// height map: black is 0 and white is 1, interpolated between pixels. Used
+// as the control of a Blend or Select, it confines a terrain to a painted
+// region with soft edges.
+type Mask struct {
+	values         []float64
+	imgW, imgH     int
+	scaleX, scaleY float64
+}
+
+// NewMask returns a Mask of img over a width x height map. Colors are read
+// as their luminance.
+func NewMask(img image.Image, width, height int) Mask {
+	b := img.Bounds()
+	m := Mask{
+		values: make([]float64, b.Dx()*b.Dy()),
+		imgW:   b.Dx(),
+		imgH:   b.Dy(),
+		scaleX: float64(b.Dx()) / float64(width),
+		scaleY: float64(b.Dy()) / float64(height),
+	}
+	for y := 0; y < m.imgH; y++ {
+		for x := 0; x < m.imgW; x++ {
+			g := color.Gray16Model.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray16)
+			m.values[y*m.imgW+x] = float64(g.Y) / 0xffff
+		}
+	}
+	return m
+}
+
+// at returns the mask value of image pixel (x, y), clamped to the image.
+func (m Mask) at(x, y int) float64 {
+	x = min(max(x, 0), m.imgW-1)
+	y = min(max(y, 0), m.imgH-1)
+	return m.values[y*m.imgW+x]
+}
+
+// Sample returns the mask value at map point (x, y).
+func (m Mask) Sample(x, y float64) float64 {
+	if len(m.values) == 0 {
+		return 0
+	}
+	// pixel centers of the map and the image line up
+	ix := (x+0.5)*m.scaleX - 0.5
+	iy := (y+0.5)*m.scaleY - 0.5
+	fx, fy := math.Floor(ix), math.Floor(iy)
+	x0, y0 := int(fx), int(fy)
+	tx, ty := ix-fx, iy-fy
+	top := m.at(x0, y0) + (m.at(x0+1, y0)-m.at(x0, y0))*tx
+	bottom := m.at(x0, y0+1) + (m.at(x0+1, y0+1)-m.at(x0, y0+1))*tx
+	return top + (bottom-top)*ty
+}
@@ -0,0 +1,74 @@
+package modules
+
+import (
+	"math"
+
+	"perlin_noise/remap"
+)
+
+// Abs outputs the absolute value of its source.
+type Abs struct {
+	Source Module
+}
+
+// Sample returns |Source|.
+func (a Abs) Sample(x, y float64) float64 { return math.Abs(a.Source.Sample(x, y)) }
+
+// Clamp limits its source to [Min, Max].
+type Clamp struct {
+	Source   Module
+	Min, Max float64
+}
+
+// Sample returns Source limited to the range.
+func (c Clamp) Sample(x, y float64) float64 {
+	return min(max(c.Source.Sample(x, y), c.Min), c.Max)
+}
+
+// ScaleBias outputs Source * Scale + Bias.
+type ScaleBias struct {
+	Source      Module
+	Scale, Bias float64
+}
+
+// Sample returns the scaled and shifted source.
+func (s ScaleBias) Sample(x, y float64) float64 {
+	return s.Source.Sample(x, y)*s.Scale + s.Bias
+}
+
+// Curve maps its source through a curve of control points.
+type Curve struct {
+	Source Module
+	Curve  *remap.Curve
+}
+
+// Sample returns Source mapped through the curve.
+func (c Curve) Sample(x, y float64) float64 { return c.Curve.At(c.Source.Sample(x, y)) }
+
+// Terrace cuts its source, expected in [0,1], into Steps terraces; see
+// remap.Terrace for Sharpness.
+type Terrace struct {
+	Source    Module
+	Steps     int
+	Sharpness float64
+}
+
+// Sample returns the terraced source.
+func (t Terrace) Sample(x, y float64) float64 {
+	return remap.Terrace(t.Source.Sample(x, y), t.Steps, t.Sharpness)
+}
+
+// Displace samples its source at a point moved by a flow field, scaled by
+// Strength. Flow returns both offsets at once so a vector field such as curl
+// noise is only evaluated once per sample.
+type Displace struct {
+	Source   Module
+	Flow     func(x, y float64) (float64, float64)
+	Strength float64
+}
+
+// Sample returns Source at the displaced point.
+func (d Displace) Sample(x, y float64) float64 {
+	dx, dy := d.Flow(x, y)
+	return d.Source.Sample(x+dx*d.Strength, y+dy*d.Strength)
+}
@@ -0,0 +1,100 @@
+// Package modules builds terrain out of small noise modules wired into a
+// graph, in the style of libnoise. Sources generate values, modifiers reshape
+// the output of one module and combiners merge several. Every module is a
+// plain struct, so a pipeline is written as one nested literal.
+package modules
+
+import (
+	"math"
+
+	"perlin_noise/perlin"
+)
+
+// Module is a node of a terrain graph: anything that yields a value at a
+// point of the map.
+type Module interface {
+	Sample(x, y float64) float64
+}
+
+// RowSampler is a Module that can fill a whole row of samples faster than
+// sampling them one by one.
+type RowSampler interface {
+	Module
+	// SampleRow fills dst with the values at (x0 + i*dx, y).
+	SampleRow(dst []float64, x0, y, dx float64)
+}
+
+// SampleRow fills dst with the values of m at (x0 + i*dx, y), in one call if
+// m is a RowSampler and one sample at a time otherwise.
+func SampleRow(m Module, dst []float64, x0, y, dx float64) {
+	if r, ok := m.(RowSampler); ok {
+		r.SampleRow(dst, x0, y, dx)
+		return
+	}
+	for i := range dst {
+		dst[i] = m.Sample(x0+float64(i)*dx, y)
+	}
+}
+
+// Func adapts a plain function to a Module.
+type Func func(x, y float64) float64
+
+// Sample calls f.
+func (f Func) Sample(x, y float64) float64 { return f(x, y) }
+
+// Const is a source with the same value everywhere.
+type Const struct {
+	Value float64
+}
+
+// Sample returns c.Value.
+func (c Const) Sample(x, y float64) float64 { return c.Value }
+
+// Fractal is a source that sums octaves of a noise basis, in approx [-1,1].
+type Fractal struct {
+	Noise perlin.NoiseFunc
+	// Style combines the octaves; nil means perlin.FBM.
+	Style perlin.FractalFunc
+	// Row, if set, fills a row of Noise in one call. SampleRow uses it when
+	// Style is nil.
+	Row         perlin.RowFunc
+	Freq        float64
+	Octaves     int
+	Persistence float64
+	Lacunarity  float64
+}
+
+// Sample evaluates the fractal at (x, y).
+func (f Fractal) Sample(x, y float64) float64 {
+	style := f.Style
+	if style == nil {
+		style = perlin.FBM
+	}
+	return style(f.Noise, x, y, f.Freq, f.Octaves, f.Persistence, f.Lacunarity)
+}
+
+// SampleRow fills dst with the fractal at (x0 + i*dx, y), a row per octave
+// when Row is set and Style is nil, with the same values as Sample.
+func (f Fractal) SampleRow(dst []float64, x0, y, dx float64) {
+	if f.Row == nil || f.Style != nil {
+		for i := range dst {
+			dst[i] = f.Sample(x0+float64(i)*dx, y)
+		}
+		return
+	}
+	perlin.FBMRow(f.Row, dst, x0, y, dx, f.Freq, f.Octaves, f.Persistence, f.Lacunarity)
+}
+
+// Radial is a source that grows with the distance from a center point:
+// (distance / Radius) ^ Exponent, so it is 1 at Radius.
+type Radial struct {
+	CenterX, CenterY float64
+	Radius           float64
+	Exponent         float64
+}
+
+// Sample returns the scaled distance of (x, y) from the center.
+func (r Radial) Sample(x, y float64) float64 {
+	dist := math.Hypot(x-r.CenterX, y-r.CenterY)
+	return math.Pow(dist/r.Radius, r.Exponent)
+}
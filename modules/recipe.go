@@ -0,0 +1,241 @@
+package modules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+
+	"perlin_noise/perlin"
+	"perlin_noise/remap"
+)
+
+// RecipeExt is the file extension of saved terrain recipes.
+const RecipeExt = ".terrain.json"
+
+// MaxOctaves is the most octaves a recipe fractal may sum. Octaves past it
+// are finer than a pixel on any map, and a shared recipe asking for millions
+// would stall generation.
+const MaxOctaves = 16
+
+// Node is the JSON form of a module, so that a whole terrain pipeline can be
+// saved as a recipe and shared. Type names the module in lower case, as in
+// "fractal", "scalebias" or "select"; only the fields that module uses are set.
+// Modifiers read their input from Sources[0], Blend and Select take A and B
+// from Sources[0] and Sources[1].
+type Node struct {
+	Type string `json:"type"`
+
+	// fractal: noise backend, octave style and octave settings. Seed is
+	// added to the seed of the map, so one recipe makes many worlds.
+	Noise       string  `json:"noise,omitempty"`
+	Style       string  `json:"style,omitempty"`
+	Seed        int64   `json:"seed,omitempty"`
+	Freq        float64 `json:"freq,omitempty"`
+	Octaves     int     `json:"octaves,omitempty"`
+	Persistence float64 `json:"persistence,omitempty"`
+	Lacunarity  float64 `json:"lacunarity,omitempty"`
+
+	// const
+	Value float64 `json:"value,omitempty"`
+	// radial: distance from the map center, 1 at the corners, to this power
+	Exponent float64 `json:"exponent,omitempty"`
+	// mask: the grayscale image to read, stretched over the map
+	Path string `json:"path,omitempty"`
+
+	// scalebias, clamp, curve and terrace
+	Scale     float64      `json:"scale,omitempty"`
+	Bias      float64      `json:"bias,omitempty"`
+	Min       float64      `json:"min,omitempty"`
+	Max       float64      `json:"max,omitempty"`
+	Points    [][2]float64 `json:"points,omitempty"`
+	Steps     int          `json:"steps,omitempty"`
+	Sharpness float64      `json:"sharpness,omitempty"`
+
+	// displace: flow field name, its frequency and the displacement in pixels
+	// (Freq and Seed also apply)
+	Flow     string  `json:"flow,omitempty"`
+	Strength float64 `json:"strength,omitempty"`
+
+	// select
+	Threshold float64 `json:"threshold,omitempty"`
+	Falloff   float64 `json:"falloff,omitempty"`
+
+	Sources []*Node `json:"sources,omitempty"`
+	Control *Node   `json:"control,omitempty"`
+}
+
+// Env resolves the names used in a recipe and gives the map size.
+type Env struct {
+	Width, Height int
+	// Noise returns the noise backend called name, seeded with the map seed plus seed.
+	Noise func(name string, seed int64) (perlin.NoiseFunc, error)
+	// Style returns the octave combiner called name.
+	Style func(name string) (perlin.FractalFunc, error)
+	// Flow returns the flow field called name at freq, seeded like Noise.
+	Flow func(name string, seed int64, freq float64) (func(x, y float64) (float64, float64), error)
+	// Image loads the image at path, for masks.
+	Image func(path string) (image.Image, error)
+}
+
+// Build turns the recipe rooted at n into a module graph.
+func (n *Node) Build(env Env) (Module, error) {
+	if n == nil {
+		return nil, errors.New("missing module")
+	}
+	m, err := n.build(env)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.Type, err)
+	}
+	return m, nil
+}
+
+// sources builds the sources of n, which needs at least count of them.
+func (n *Node) sources(env Env, count int) ([]Module, error) {
+	if len(n.Sources) < count {
+		return nil, fmt.Errorf("needs at least %d sources, got %d", count, len(n.Sources))
+	}
+	out := make([]Module, len(n.Sources))
+	for i, src := range n.Sources {
+		m, err := src.Build(env)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = m
+	}
+	return out, nil
+}
+
+func (n *Node) build(env Env) (Module, error) {
+	switch n.Type {
+	case "const":
+		return Const{Value: n.Value}, nil
+
+	case "fractal":
+		if n.Octaves < 1 || n.Octaves > MaxOctaves {
+			return nil, fmt.Errorf("octaves must be in [1, %d], got %d", MaxOctaves, n.Octaves)
+		}
+		if !finite(n.Freq) || n.Freq <= 0 {
+			return nil, fmt.Errorf("freq must be greater than 0, got %g", n.Freq)
+		}
+		if !finite(n.Lacunarity) || n.Lacunarity <= 0 {
+			return nil, fmt.Errorf("lacunarity must be greater than 0, got %g", n.Lacunarity)
+		}
+		if !finite(n.Persistence) {
+			return nil, fmt.Errorf("persistence must be finite, got %g", n.Persistence)
+		}
+		noise, err := env.Noise(n.Noise, n.Seed)
+		if err != nil {
+			return nil, err
+		}
+		style, err := env.Style(n.Style)
+		if err != nil {
+			return nil, err
+		}
+		return Fractal{Noise: noise, Style: style, Freq: n.Freq, Octaves: n.Octaves, Persistence: n.Persistence, Lacunarity: n.Lacunarity}, nil
+
+	case "radial":
+		cx, cy := float64(env.Width)/2, float64(env.Height)/2
+		return Radial{CenterX: cx, CenterY: cy, Radius: math.Hypot(cx, cy), Exponent: n.Exponent}, nil
+
+	case "mask":
+		img, err := env.Image(n.Path)
+		if err != nil {
+			return nil, err
+		}
+		return NewMask(img, env.Width, env.Height), nil
+
+	case "abs", "clamp", "scalebias", "curve", "terrace", "displace":
+		src, err := n.sources(env, 1)
+		if err != nil {
+			return nil, err
+		}
+		return n.modifier(env, src[0])
+
+	case "add", "multiply", "min", "max":
+		src, err := n.sources(env, 1)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Type {
+		case "add":
+			return Add{Sources: src}, nil
+		case "multiply":
+			return Multiply{Sources: src}, nil
+		case "min":
+			return Min{Sources: src}, nil
+		default:
+			return Max{Sources: src}, nil
+		}
+
+	case "blend", "select":
+		src, err := n.sources(env, 2)
+		if err != nil {
+			return nil, err
+		}
+		control, err := n.Control.Build(env)
+		if err != nil {
+			return nil, fmt.Errorf("control: %w", err)
+		}
+		if n.Type == "blend" {
+			return Blend{A: src[0], B: src[1], Control: control}, nil
+		}
+		return Select{A: src[0], B: src[1], Control: control, Threshold: n.Threshold, Falloff: n.Falloff}, nil
+	}
+	return nil, fmt.Errorf("unknown module type %q", n.Type)
+}
+
+// modifier builds the modifier n around src.
+func (n *Node) modifier(env Env, src Module) (Module, error) {
+	switch n.Type {
+	case "abs":
+		return Abs{Source: src}, nil
+	case "clamp":
+		if n.Min > n.Max {
+			return nil, fmt.Errorf("min (%g) must not be above max (%g)", n.Min, n.Max)
+		}
+		return Clamp{Source: src, Min: n.Min, Max: n.Max}, nil
+	case "scalebias":
+		return ScaleBias{Source: src, Scale: n.Scale, Bias: n.Bias}, nil
+	case "curve":
+		curve, err := remap.NewCurve(n.Points...)
+		if err != nil {
+			return nil, err
+		}
+		return Curve{Source: src, Curve: curve}, nil
+	case "terrace":
+		return Terrace{Source: src, Steps: n.Steps, Sharpness: n.Sharpness}, nil
+	default:
+		flow, err := env.Flow(n.Flow, n.Seed, n.Freq)
+		if err != nil {
+			return nil, err
+		}
+		return Displace{Source: src, Flow: flow, Strength: n.Strength}, nil
+	}
+}
+
+// finite reports whether v is neither NaN nor infinite.
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// ReadRecipe decodes a recipe saved by WriteRecipe. Unknown fields are
+// rejected so that typos do not pass silently.
+func ReadRecipe(r io.Reader) (*Node, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var n Node
+	if err := dec.Decode(&n); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// WriteRecipe encodes the recipe rooted at n as indented JSON.
+func WriteRecipe(w io.Writer, n *Node) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(n)
+}
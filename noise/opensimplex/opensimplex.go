@@ -0,0 +1,191 @@
+// Package opensimplex implements 2D OpenSimplex2 noise in its two variants:
+// OpenSimplex2F, the fast one, and OpenSimplex2S, the smoother one. Both expose
+// the same methods as perlin.Perlin so either can stand in for it.
+package opensimplex
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Variant selects between the two OpenSimplex2 kernels.
+type Variant int
+
+const (
+	// F sums three lattice points per sample with a small kernel. It is fast
+	// but shows slightly more directional artifacts.
+	F Variant = iota
+	// S sums four lattice points with a wider kernel. It is smoother but slower.
+	S
+)
+
+// Skew and unskew factors of the 2D triangular lattice.
+var (
+	skew   = 0.5 * (math.Sqrt(3) - 1)
+	unskew = (1/math.Sqrt(3) - 1) / 2
+)
+
+// squared kernel radii of the two variants
+const (
+	radiusF = 0.5
+	radiusS = 2.0 / 3.0
+)
+
+// scale factors that bring the raw sums to approximately [-1, 1]
+const (
+	scaleF = 99.2
+	scaleS = 18.1
+)
+
+// grads are 24 unit gradient directions evenly spread around the circle.
+var grads = func() [24][2]float64 {
+	var g [24][2]float64
+	for i := range g {
+		a := float64(i) * 2 * math.Pi / 24
+		g[i] = [2]float64{math.Cos(a), math.Sin(a)}
+	}
+	return g
+}()
+
+// Noise holds the duplicated permutation table (512 entries) and the variant.
+type Noise struct {
+	p       []int
+	variant Variant
+}
+
+// New creates an OpenSimplex2 instance of the given variant seeded deterministically.
+func New(seed int64, variant Variant) *Noise {
+	r := rand.New(rand.NewSource(seed))
+	base := r.Perm(256)
+
+	n := &Noise{p: make([]int, 512), variant: variant}
+	for i := 0; i < 256; i++ {
+		n.p[i] = base[i]
+		n.p[256+i] = base[i]
+	}
+	return n
+}
+
+// contribution returns the kernel-weighted gradient of lattice point (i, j)
+// at offset (dx, dy), or 0 outside the kernel radius r2.
+func (n *Noise) contribution(i, j int, dx, dy, r2 float64) float64 {
+	a := r2 - dx*dx - dy*dy
+	if a <= 0 {
+		return 0
+	}
+	g := grads[n.p[n.p[i&255]+j&255]%len(grads)]
+	a *= a
+	return a * a * (g[0]*dx + g[1]*dy)
+}
+
+// Noise2DRaw returns 2D OpenSimplex2 noise approximately in [-1, 1].
+// x,y are world coords; freq is frequency multiplier (larger freq -> more detail).
+func (n *Noise) Noise2DRaw(x, y, freq float64) float64 {
+	xf := x * freq
+	yf := y * freq
+
+	// skew onto the lattice
+	s := (xf + yf) * skew
+	xs := xf + s
+	ys := yf + s
+	i := int(math.Floor(xs))
+	j := int(math.Floor(ys))
+	xi := xs - float64(i)
+	yi := ys - float64(j)
+
+	// offset to the cell origin, back in input space
+	t := (xi + yi) * unskew
+	dx0 := xi + t
+	dy0 := yi + t
+
+	if n.variant == S {
+		return n.noiseS(i, j, xi, yi, t, dx0, dy0) * scaleS
+	}
+	return n.noiseF(i, j, dx0, dy0) * scaleF
+}
+
+// noiseF sums the three vertices of the triangle containing the point.
+func (n *Noise) noiseF(i, j int, dx0, dy0 float64) float64 {
+	value := n.contribution(i, j, dx0, dy0, radiusF)
+	value += n.contribution(i+1, j+1, dx0-(1+2*unskew), dy0-(1+2*unskew), radiusF)
+	if dy0 > dx0 {
+		value += n.contribution(i, j+1, dx0-unskew, dy0-(unskew+1), radiusF)
+	} else {
+		value += n.contribution(i+1, j, dx0-(unskew+1), dy0-unskew, radiusF)
+	}
+	return value
+}
+
+// noiseS sums the four lattice points whose wider kernels can reach the point.
+func (n *Noise) noiseS(i, j int, xi, yi, t, dx0, dy0 float64) float64 {
+	value := n.contribution(i, j, dx0, dy0, radiusS)
+	value += n.contribution(i+1, j+1, dx0-(1+2*unskew), dy0-(1+2*unskew), radiusS)
+
+	xmyi := xi - yi
+	if t < unskew {
+		// upper triangle of the cell
+		if xi+xmyi > 1 {
+			value += n.contribution(i+2, j+1, dx0-(3*unskew+2), dy0-(3*unskew+1), radiusS)
+		} else {
+			value += n.contribution(i, j+1, dx0-unskew, dy0-(unskew+1), radiusS)
+		}
+		if yi-xmyi > 1 {
+			value += n.contribution(i+1, j+2, dx0-(3*unskew+1), dy0-(3*unskew+2), radiusS)
+		} else {
+			value += n.contribution(i+1, j, dx0-(unskew+1), dy0-unskew, radiusS)
+		}
+	} else {
+		// lower triangle of the cell
+		if xi+xmyi < 0 {
+			value += n.contribution(i-1, j, dx0+(1+unskew), dy0+unskew, radiusS)
+		} else {
+			value += n.contribution(i+1, j, dx0-(unskew+1), dy0-unskew, radiusS)
+		}
+		if yi < xmyi {
+			value += n.contribution(i, j-1, dx0+unskew, dy0+(unskew+1), radiusS)
+		} else {
+			value += n.contribution(i, j+1, dx0-unskew, dy0-(unskew+1), radiusS)
+		}
+	}
+	return value
+}
+
+// Noise2D returns normalized OpenSimplex2 noise in [0,1] (wrapper around Noise2DRaw).
+func (n *Noise) Noise2D(x, y, freq float64) float64 {
+	return (n.Noise2DRaw(x, y, freq) + 1.0) * 0.5
+}
+
+// FBM2DRaw returns fractal brownian motion using raw OpenSimplex2 noise in approx [-1,1].
+// octaves is integer number of octaves; persistence < 1 reduces amplitude each octave;
+// lacunarity > 1 increases frequency each octave.
+func (n *Noise) FBM2DRaw(x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	total := 0.0
+	amplitude := 1.0
+	frequency := baseFreq
+	maxAmp := 0.0
+
+	for i := 0; i < octaves; i++ {
+		total += n.Noise2DRaw(x, y, frequency) * amplitude
+		maxAmp += amplitude
+		amplitude *= persistence
+		frequency *= lacunarity
+	}
+
+	if maxAmp == 0 {
+		return 0
+	}
+	return total / maxAmp
+}
+
+// FBM2D accepts octaves as a float like perlin.Perlin.FBM2D and returns [0,1].
+func (n *Noise) FBM2D(x, y, baseFreq, octaves, persistence, lacunarity float64) float64 {
+	raw := n.FBM2DRaw(x, y, baseFreq, int(octaves), persistence, lacunarity)
+	return (raw + 1.0) * 0.5
+}
+
+// NoiseFlow returns a signed 2D flow vector in approximately [-1,1] per component.
+func (n *Noise) NoiseFlow(x, y, freq float64) (float64, float64) {
+	xFlow := n.Noise2DRaw(x, y, freq)
+	yFlow := n.Noise2DRaw(x+100.0, y+100.0, freq)
+	return xFlow, yFlow
+}
@@ -0,0 +1,97 @@
+package perlin
+
+import "math"
+
+// diagonals is the classic gradient set in the order grad picks it.
+var diagonals = [4][2]float64{{1, 1}, {-1, 1}, {1, -1}, {-1, -1}}
+
+// FillRow fills dst with Noise2DRaw(x0 + i*dx, y, freq) for every index i, with
+// identical results. The row's lattice terms are computed once, and the corner
+// gradients are only looked up again when a sample crosses into a new cell,
+// without branching; the saving is largest at high frequencies, where the
+// per-sample lookups dominate.
+func (p *Perlin) FillRow(dst []float64, x0, y, dx, freq float64) {
+	yf := y * freq
+	yFloor := math.Floor(yf)
+	yi := int(yFloor) & 255
+	yf -= yFloor
+	// coordinates off the lattice give 0, as in Noise2DRaw
+	if math.IsNaN(yf) {
+		clear(dst)
+		return
+	}
+	v := fade(yf)
+
+	// the corner gradients, looked up without branching
+	grads := p.grads
+	if grads == nil {
+		grads = diagonals[:]
+	}
+	mask := len(grads) - 1
+
+	cell := math.MinInt
+	var ga, gb, gc, gd [2]float64
+	for i := range dst {
+		xf := (x0 + float64(i)*dx) * freq
+		xFloor := math.Floor(xf)
+		if c := int(xFloor); c != cell {
+			cell = c
+			xi := c & 255
+			ga = grads[p.p[p.p[xi]+yi]&mask]
+			gb = grads[p.p[p.p[xi+1]+yi]&mask]
+			gc = grads[p.p[p.p[xi]+yi+1]&mask]
+			gd = grads[p.p[p.p[xi+1]+yi+1]&mask]
+		}
+		xf -= xFloor
+		if math.IsNaN(xf) {
+			dst[i] = 0
+			continue
+		}
+		u := fade(xf)
+
+		x1 := lerp(u, ga[0]*xf+ga[1]*yf, gb[0]*(xf-1)+gb[1]*yf)
+		x2 := lerp(u, gc[0]*xf+gc[1]*(yf-1), gd[0]*(xf-1)+gd[1]*(yf-1))
+		dst[i] = lerp(v, x1, x2)
+	}
+}
+
+// FillGrid fills dst, a row-major tile width samples wide, with Noise2DRaw on
+// a grid starting at (x0, y0) with spacing step, one FillRow per row.
+func (p *Perlin) FillGrid(dst []float64, width int, x0, y0, step, freq float64) {
+	for r := 0; r*width < len(dst); r++ {
+		row := dst[r*width : min((r+1)*width, len(dst))]
+		p.FillRow(row, x0, y0+float64(r)*step, step, freq)
+	}
+}
+
+// RowFunc fills dst with a noise basis at (x0 + i*dx, y) and frequency freq,
+// as FillRow does for Noise2DRaw.
+type RowFunc func(dst []float64, x0, y, dx, freq float64)
+
+// FBMRow fills dst with FBM at (x0 + i*dx, y) over the noise that fill
+// computes a row of, one fill per octave. Each value is summed in the same
+// order as FBM sums it, so it equals FBM over the matching NoiseFunc.
+func FBMRow(fill RowFunc, dst []float64, x0, y, dx, baseFreq float64, octaves int, persistence, lacunarity float64) {
+	clear(dst)
+	octave := make([]float64, len(dst))
+	amplitude := 1.0
+	frequency := baseFreq
+	maxAmp := 0.0
+
+	for i := 0; i < octaves; i++ {
+		fill(octave, x0, y, dx, frequency)
+		for j, v := range octave {
+			dst[j] += v * amplitude
+		}
+		maxAmp += amplitude
+		amplitude *= persistence
+		frequency *= lacunarity
+	}
+
+	if maxAmp == 0 {
+		return
+	}
+	for j := range dst {
+		dst[j] /= maxAmp
+	}
+}
@@ -0,0 +1,70 @@
+package perlin
+
+import (
+	"math"
+	"testing"
+)
+
+// rowCases are rows that start off the lattice, run backwards, cross many
+// cells per sample or stay within one, and leave the lattice.
+var rowCases = []struct{ x0, y, dx, freq float64 }{
+	{0, 0, 1, 0.05},
+	{-37.5, 12.25, 1, 0.013},
+	{500, -80, -3, 0.21},
+	{1.5, 2.5, 0.01, 1},
+	{0, 7, 17, 1.9},
+	{1e6, 3, 1, 0.5},
+	{0, math.NaN(), 1, 0.1},
+	{math.Inf(-1), 1, 1, 0.1},
+}
+
+// TestFillRow checks that FillRow gives exactly Noise2DRaw at every sample,
+// for every gradient set.
+func TestFillRow(t *testing.T) {
+	for _, g := range GradientSets {
+		p := NewPerlin(7, WithGradients(g))
+		for _, c := range rowCases {
+			row := make([]float64, 64)
+			p.FillRow(row, c.x0, c.y, c.dx, c.freq)
+			for i, got := range row {
+				if want := p.Noise2DRaw(c.x0+float64(i)*c.dx, c.y, c.freq); got != want {
+					t.Fatalf("gradients %d, row %+v: sample %d = %v, Noise2DRaw gives %v", g, c, i, got, want)
+				}
+			}
+		}
+	}
+}
+
+// TestFillGrid checks FillGrid against Noise2DRaw over a tile whose last row
+// is cut short.
+func TestFillGrid(t *testing.T) {
+	p := NewPerlin(11, WithGradients(Gradients8))
+	const width, step, freq = 13, 2.5, 0.07
+	x0, y0 := -20.0, 33.0
+	grid := make([]float64, width*9+5)
+	p.FillGrid(grid, width, x0, y0, step, freq)
+	for i, got := range grid {
+		x := x0 + float64(i%width)*step
+		y := y0 + float64(i/width)*step
+		if want := p.Noise2DRaw(x, y, freq); got != want {
+			t.Fatalf("cell %d = %v, Noise2DRaw gives %v", i, got, want)
+		}
+	}
+}
+
+// TestFBMRow checks that FBMRow over FillRow gives exactly FBM over
+// Noise2DRaw.
+func TestFBMRow(t *testing.T) {
+	p := NewPerlin(3)
+	for _, c := range rowCases {
+		for _, octaves := range []int{0, 1, 5, 9} {
+			row := make([]float64, 48)
+			FBMRow(p.FillRow, row, c.x0, c.y, c.dx, c.freq, octaves, 0.5, 2)
+			for i, got := range row {
+				if want := FBM(p.Noise2DRaw, c.x0+float64(i)*c.dx, c.y, c.freq, octaves, 0.5, 2); got != want {
+					t.Fatalf("row %+v, %d octaves: sample %d = %v, FBM gives %v", c, octaves, i, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package perlin
+
+// curlScale brings the curl of Noise2DRaw to approximately [-1,1] per component.
+const curlScale = 0.35
+
+// CurlFlow returns a divergence-free 2D flow vector in approximately [-1,1] per
+// component: the curl (dN/dy, -dN/dx) of the noise potential N. Unlike
+// NoiseFlow, whose two independent channels squeeze and stretch the terrain,
+// curl flow only swirls it along the potential's contour lines.
+func (p *Perlin) CurlFlow(x, y, freq float64) (float64, float64) {
+	if freq == 0 {
+		return 0, 0
+	}
+	// slopes per noise-space unit, so the strength does not depend on freq
+	_, dx, dy := p.Noise2DDeriv(x, y, freq)
+	return dy / freq * curlScale, -dx / freq * curlScale
+}
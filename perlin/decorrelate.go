@@ -0,0 +1,47 @@
+package perlin
+
+import "math"
+
+// octaveRotations are the rotations Decorrelated picks from: multiples of the
+// golden angle, so none is a multiple of a right angle and every octave's
+// lattice ends up skewed against the others.
+var octaveRotations = func() [16][2]float64 {
+	golden := math.Pi * (3 - math.Sqrt(5))
+	var r [16][2]float64
+	for i := range r {
+		a := golden * float64(i+1)
+		r[i] = [2]float64{math.Cos(a), math.Sin(a)}
+	}
+	return r
+}()
+
+// mix64 is the splitmix64 finalizer, a cheap and well-spread 64-bit hash.
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// Decorrelated wraps a noise basis so that each frequency samples it rotated
+// and offset by an amount derived from seed and that frequency. The octaves
+// of a fractal sum run at harmonically related frequencies over the same
+// lattice, so without this their grid artifacts line up; with it every octave
+// sees the lattice at its own angle and position.
+//
+// The rotation breaks periodic and spherical sampling, so do not wrap
+// Noise2DPeriodic or Equirectangular.
+func Decorrelated(noise NoiseFunc, seed int64) NoiseFunc {
+	return func(x, y, freq float64) float64 {
+		h := mix64(uint64(seed) ^ math.Float64bits(freq))
+		r := octaveRotations[h&15]
+		// offsets in lattice cells, within one period of the permutation table
+		ox := float64(h>>8&0xffff) * (256.0 / 65536)
+		oy := float64(h>>24&0xffff) * (256.0 / 65536)
+
+		xf, yf := x*freq, y*freq
+		return noise(xf*r[0]-yf*r[1]+ox, xf*r[1]+yf*r[0]+oy, 1)
+	}
+}
@@ -0,0 +1,86 @@
+package perlin
+
+import "math"
+
+// fadeDeriv is the derivative of fade: 30t^2(t-1)^2.
+func fadeDeriv(t float64) float64 {
+	return 30 * t * t * (t - 1) * (t - 1)
+}
+
+// gradVec returns the gradient vector grad picks for hash.
+func (p *Perlin) gradVec(hash int) (float64, float64) {
+	if p.grads != nil {
+		g := p.grads[hash&(len(p.grads)-1)]
+		return g[0], g[1]
+	}
+	switch hash & 3 {
+	case 0:
+		return 1, 1
+	case 1:
+		return -1, 1
+	case 2:
+		return 1, -1
+	default: // case 3
+		return -1, -1
+	}
+}
+
+// Noise2DDeriv returns Noise2DRaw at (x, y) together with its exact partial
+// derivatives with respect to x and y, for the cost of one noise evaluation.
+func (p *Perlin) Noise2DDeriv(x, y, freq float64) (value, dx, dy float64) {
+	xf := x * freq
+	yf := y * freq
+
+	xi := int(math.Floor(xf)) & 255
+	yi := int(math.Floor(yf)) & 255
+
+	xf = xf - math.Floor(xf)
+	yf = yf - math.Floor(yf)
+
+	u := fade(xf)
+	v := fade(yf)
+	du := fadeDeriv(xf)
+	dv := fadeDeriv(yf)
+
+	// corner gradients and their dot products with the offsets
+	gax, gay := p.gradVec(p.p[p.p[xi]+yi])
+	gbx, gby := p.gradVec(p.p[p.p[xi+1]+yi])
+	gcx, gcy := p.gradVec(p.p[p.p[xi]+yi+1])
+	gdx, gdy := p.gradVec(p.p[p.p[xi+1]+yi+1])
+	a := gax*xf + gay*yf
+	b := gbx*(xf-1) + gby*yf
+	c := gcx*xf + gcy*(yf-1)
+	d := gdx*(xf-1) + gdy*(yf-1)
+
+	// bilinear blend a + u(b-a) + v(c-a) + uv(a-b-c+d), differentiated
+	k := a - b - c + d
+	value = a + u*(b-a) + v*(c-a) + u*v*k
+	dx = gax + u*(gbx-gax) + v*(gcx-gax) + u*v*(gax-gbx-gcx+gdx) + du*(b-a+v*k)
+	dy = gay + u*(gby-gay) + v*(gcy-gay) + u*v*(gay-gby-gcy+gdy) + dv*(c-a+u*k)
+
+	// chain rule back to world coords
+	return value, dx * freq, dy * freq
+}
+
+// FBM2DDeriv returns FBM2DRaw at (x, y) together with its partial derivatives
+// with respect to x and y.
+func (p *Perlin) FBM2DDeriv(x, y, baseFreq float64, octaves int, persistence, lacunarity float64) (value, dx, dy float64) {
+	amplitude := 1.0
+	frequency := baseFreq
+	maxAmp := 0.0
+
+	for i := 0; i < octaves; i++ {
+		n, nx, ny := p.Noise2DDeriv(x, y, frequency)
+		value += n * amplitude
+		dx += nx * amplitude
+		dy += ny * amplitude
+		maxAmp += amplitude
+		amplitude *= persistence
+		frequency *= lacunarity
+	}
+
+	if maxAmp == 0 {
+		return 0, 0, 0
+	}
+	return value / maxAmp, dx / maxAmp, dy / maxAmp
+}
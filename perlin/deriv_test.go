@@ -0,0 +1,61 @@
+package perlin
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// centralDiff returns the partial derivatives of f at (x, y) by central
+// differences with step h.
+func centralDiff(f func(x, y float64) float64, x, y, h float64) (float64, float64) {
+	return (f(x+h, y) - f(x-h, y)) / (2 * h), (f(x, y+h) - f(x, y-h)) / (2 * h)
+}
+
+// TestNoise2DDeriv checks that Noise2DDeriv gives Noise2DRaw, up to rounding,
+// and matches central differences of it, for every gradient set.
+func TestNoise2DDeriv(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, g := range GradientSets {
+		p := NewPerlin(3, WithGradients(g))
+		for _, freq := range []float64{0.01, 0.2, 1.7} {
+			noise := func(x, y float64) float64 { return p.Noise2DRaw(x, y, freq) }
+			for range 200 {
+				x, y := (r.Float64()-0.5)*2000, (r.Float64()-0.5)*2000
+				value, dx, dy := p.Noise2DDeriv(x, y, freq)
+				if want := noise(x, y); math.Abs(value-want) > 1e-12 {
+					t.Fatalf("gradients %d, freq %v: value %v at (%v, %v), Noise2DRaw gives %v", g, freq, value, x, y, want)
+				}
+				// the step is a fixed fraction of a lattice cell
+				wantDx, wantDy := centralDiff(noise, x, y, 1e-5/freq)
+				if math.Abs(dx-wantDx) > 1e-6*freq || math.Abs(dy-wantDy) > 1e-6*freq {
+					t.Fatalf("gradients %d, freq %v: derivatives (%v, %v) at (%v, %v), central differences give (%v, %v)", g, freq, dx, dy, x, y, wantDx, wantDy)
+				}
+			}
+		}
+	}
+}
+
+// TestFBM2DDeriv checks FBM2DDeriv against FBM2DRaw and central differences
+// of it, with the 16-gradient set among others.
+func TestFBM2DDeriv(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for _, g := range []Gradients{4, 16} {
+		p := NewPerlin(5, WithGradients(g))
+		const freq, octaves, persistence, lacunarity = 0.02, 6, 0.5, 2.0
+		fbm := func(x, y float64) float64 { return p.FBM2DRaw(x, y, freq, octaves, persistence, lacunarity) }
+		// the finest octave sets the scale of the step and the error
+		top := freq * math.Pow(lacunarity, octaves-1)
+		for range 200 {
+			x, y := (r.Float64()-0.5)*2000, (r.Float64()-0.5)*2000
+			value, dx, dy := p.FBM2DDeriv(x, y, freq, octaves, persistence, lacunarity)
+			if want := fbm(x, y); math.Abs(value-want) > 1e-12 {
+				t.Fatalf("gradients %d: value %v at (%v, %v), FBM2DRaw gives %v", g, value, x, y, want)
+			}
+			wantDx, wantDy := centralDiff(fbm, x, y, 1e-5/top)
+			if math.Abs(dx-wantDx) > 1e-6*top || math.Abs(dy-wantDy) > 1e-6*top {
+				t.Fatalf("gradients %d: derivatives (%v, %v) at (%v, %v), central differences give (%v, %v)", g, dx, dy, x, y, wantDx, wantDy)
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package perlin
+
+// NoiseFunc is a signed noise basis in approx [-1,1], such as Perlin.Noise2DRaw.
+type NoiseFunc func(x, y, freq float64) float64
+
+// FractalFunc combines octaves of a noise basis, such as FBM or Ridged.
+type FractalFunc func(noise NoiseFunc, x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64
+
+// ridgedGain controls how strongly a ridge in one octave sharpens the next.
+const ridgedGain = 2.0
+
+// FBM sums octaves of the noise basis, normalized by the total amplitude.
+func FBM(noise NoiseFunc, x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	total := 0.0
+	amplitude := 1.0
+	frequency := baseFreq
+	maxAmp := 0.0
+
+	for i := 0; i < octaves; i++ {
+		total += noise(x, y, frequency) * amplitude
+		maxAmp += amplitude
+		amplitude *= persistence
+		frequency *= lacunarity
+	}
+
+	if maxAmp == 0 {
+		return 0
+	}
+	return total / maxAmp
+}
+
+// Ridged is a ridged multifractal in approx [-1,1]. Each octave folds the
+// noise into sharp crests with (1 - |noise|)^2 and is weighted by the octave
+// before it, so fine detail gathers along the ridgelines and valleys stay smooth.
+func Ridged(noise NoiseFunc, x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	total := 0.0
+	amplitude := 1.0
+	frequency := baseFreq
+	maxAmp := 0.0
+	weight := 1.0
+
+	for i := 0; i < octaves; i++ {
+		n := noise(x, y, frequency)
+		if n < 0 {
+			n = -n
+		}
+		signal := (1 - n) * (1 - n) * weight
+		weight = min(max(signal*ridgedGain, 0), 1)
+
+		total += signal * amplitude
+		maxAmp += amplitude
+		amplitude *= persistence
+		frequency *= lacunarity
+	}
+
+	if maxAmp == 0 {
+		return 0
+	}
+	// signals are in [0,1]; recenter to match FBM
+	return total/maxAmp*2 - 1
+}
+
+// Billow is FBM over |noise|*2-1, folding every octave into rounded,
+// puffy bumps. It suits rolling lowlands and cloud masks.
+func Billow(noise NoiseFunc, x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	folded := func(x, y, freq float64) float64 {
+		n := noise(x, y, freq)
+		if n < 0 {
+			n = -n
+		}
+		return n*2 - 1
+	}
+	return FBM(folded, x, y, baseFreq, octaves, persistence, lacunarity)
+}
+
+// Hybrid is a Musgrave-style hybrid multifractal in approx [-1,1]. Each octave
+// is shifted up by offset and weighted by the running product of the octaves
+// before it (scaled by gain), so detail is damped in low valleys and builds up
+// on peaks. Higher offsets give more uniformly rough terrain.
+func Hybrid(noise NoiseFunc, x, y, baseFreq float64, octaves int, persistence, lacunarity, offset, gain float64) float64 {
+	total := 0.0
+	amplitude := 1.0
+	frequency := baseFreq
+	weightSum := 0.0
+	weight := 1.0
+
+	for i := 0; i < octaves; i++ {
+		signal := noise(x, y, frequency) + offset
+		total += weight * amplitude * signal
+		weightSum += weight * amplitude
+		weight = min(max(weight*signal*gain, 0), 1)
+
+		amplitude *= persistence
+		frequency *= lacunarity
+	}
+
+	if weightSum == 0 {
+		return 0
+	}
+	// a weighted mean of the shifted octaves; remove the shift again
+	return total/weightSum - offset
+}
+
+// FBM2DRidged is the ridged multifractal counterpart of FBM2DRaw.
+func (p *Perlin) FBM2DRidged(x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	return Ridged(p.Noise2DRaw, x, y, baseFreq, octaves, persistence, lacunarity)
+}
+
+// FBM2DBillow is the billow counterpart of FBM2DRaw.
+func (p *Perlin) FBM2DBillow(x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	return Billow(p.Noise2DRaw, x, y, baseFreq, octaves, persistence, lacunarity)
+}
+
+// FBM2DHybrid is the hybrid multifractal counterpart of FBM2DRaw.
+func (p *Perlin) FBM2DHybrid(x, y, baseFreq float64, octaves int, persistence, lacunarity, offset, gain float64) float64 {
+	return Hybrid(p.Noise2DRaw, x, y, baseFreq, octaves, persistence, lacunarity, offset, gain)
+}
+
+// Turbulence sums the absolute value of each octave, normalized by the total
+// amplitude, giving a value in [0,1] with sharp creases wherever an octave
+// crosses zero. Use it to distort coordinates for marble-like patterns, or
+// add it to heights for rough, eroded badlands.
+func Turbulence(noise NoiseFunc, x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	abs := func(x, y, freq float64) float64 {
+		n := noise(x, y, freq)
+		if n < 0 {
+			return -n
+		}
+		return n
+	}
+	return FBM(abs, x, y, baseFreq, octaves, persistence, lacunarity)
+}
+
+// Turbulence2D is Turbulence over Noise2DRaw; it returns [0,1].
+func (p *Perlin) Turbulence2D(x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	return Turbulence(p.Noise2DRaw, x, y, baseFreq, octaves, persistence, lacunarity)
+}
+
+// EffectiveOctaves returns how many of the first octaves of a fractal sum
+// carry at least threshold of its total amplitude. The octaves after them are
+// too faint to see and can be skipped. It never returns fewer than one.
+func EffectiveOctaves(octaves int, persistence, threshold float64) int {
+	maxAmp := 0.0
+	amplitude := 1.0
+	for i := 0; i < octaves; i++ {
+		maxAmp += amplitude
+		amplitude *= persistence
+	}
+
+	amplitude = 1.0
+	for i := 1; i < octaves; i++ {
+		amplitude *= persistence
+		if amplitude < threshold*maxAmp {
+			return i
+		}
+	}
+	return max(octaves, 1)
+}
@@ -0,0 +1,29 @@
+package perlin
+
+import "testing"
+
+// TestEffectiveOctaves checks that high octave counts are cut back and that
+// the octaves that remain visible are kept.
+func TestEffectiveOctaves(t *testing.T) {
+	const threshold = 1.0 / 512
+	tests := []struct {
+		octaves     int
+		persistence float64
+		want        int
+	}{
+		// at high counts the last octaves drop below 1/512 of the sum
+		{16, 0.5, 9},
+		{16, 0.3, 5},
+		{16, 0.7, 15},
+		// few octaves, or slowly fading ones, are all kept
+		{8, 0.7, 8},
+		{4, 0.5, 4},
+		{1, 0.5, 1},
+		{0, 0.5, 1},
+	}
+	for _, tt := range tests {
+		if got := EffectiveOctaves(tt.octaves, tt.persistence, threshold); got != tt.want {
+			t.Errorf("EffectiveOctaves(%d, %v) = %d, want %d", tt.octaves, tt.persistence, got, tt.want)
+		}
+	}
+}
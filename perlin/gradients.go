@@ -0,0 +1,43 @@
+package perlin
+
+import "math"
+
+// Gradients is the number of gradient directions Perlin noise picks from.
+type Gradients int
+
+// Gradient sets selectable with WithGradients.
+const (
+	// Gradients4 is the classic set of the four diagonals. It is the fastest
+	// but shows diamond-shaped artifacts.
+	Gradients4 Gradients = 4
+	// Gradients8 adds the axes to the diagonals, as in Ken Perlin's improved noise.
+	Gradients8 Gradients = 8
+	// Gradients16 spreads sixteen directions evenly around the circle.
+	Gradients16 Gradients = 16
+)
+
+// GradientSets lists the valid gradient sets.
+var GradientSets = []Gradients{Gradients4, Gradients8, Gradients16}
+
+// Option configures a Perlin instance built by NewPerlin.
+type Option func(*Perlin)
+
+// WithGradients selects the gradient set; unknown sets fall back to Gradients4.
+// Every set uses vectors of the same length, so the output range is unchanged.
+func WithGradients(g Gradients) Option {
+	return func(p *Perlin) {
+		switch g {
+		case Gradients8:
+			s := math.Sqrt2
+			p.grads = [][2]float64{{1, 1}, {-1, 1}, {1, -1}, {-1, -1}, {s, 0}, {-s, 0}, {0, s}, {0, -s}}
+		case Gradients16:
+			p.grads = make([][2]float64, 16)
+			for i := range p.grads {
+				a := float64(i) * 2 * math.Pi / 16
+				p.grads[i] = [2]float64{math.Sqrt2 * math.Cos(a), math.Sqrt2 * math.Sin(a)}
+			}
+		default:
+			p.grads = nil
+		}
+	}
+}
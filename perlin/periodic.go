@@ -0,0 +1,53 @@
+package perlin
+
+import "math"
+
+// wrap returns i modulo n in [0, n).
+func wrap(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+	return i
+}
+
+// Noise2DPeriodic is Noise2DRaw made to repeat every period world units along
+// both axes, so maps built from it tile seamlessly. freq is rounded to the
+// nearest frequency that fits a whole number of lattice cells (at least one)
+// into period; the gradients then wrap around at the period.
+func (p *Perlin) Noise2DPeriodic(x, y, freq, period float64) float64 {
+	cells := max(1, int(math.Round(freq*period)))
+	f := float64(cells) / period
+
+	xf := x * f
+	yf := y * f
+
+	x0 := wrap(int(math.Floor(xf)), cells)
+	y0 := wrap(int(math.Floor(yf)), cells)
+	x1 := (x0 + 1) % cells
+	y1 := (y0 + 1) % cells
+	x0, y0, x1, y1 = x0&255, y0&255, x1&255, y1&255
+
+	xf = xf - math.Floor(xf)
+	yf = yf - math.Floor(yf)
+
+	u := fade(xf)
+	v := fade(yf)
+
+	aa := p.p[p.p[x0]+y0]
+	ab := p.p[p.p[x0]+y1]
+	ba := p.p[p.p[x1]+y0]
+	bb := p.p[p.p[x1]+y1]
+
+	r1 := lerp(u, p.grad(aa, xf, yf), p.grad(ba, xf-1, yf))
+	r2 := lerp(u, p.grad(ab, xf, yf-1), p.grad(bb, xf-1, yf-1))
+
+	return lerp(v, r1, r2)
+}
+
+// NoiseFlowPeriodic is NoiseFlow built on Noise2DPeriodic.
+func (p *Perlin) NoiseFlowPeriodic(x, y, freq, period float64) (float64, float64) {
+	xFlow := p.Noise2DPeriodic(x, y, freq, period)
+	yFlow := p.Noise2DPeriodic(x+100.0, y+100.0, freq, period)
+	return xFlow, yFlow
+}
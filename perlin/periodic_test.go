@@ -0,0 +1,64 @@
+package perlin
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// TestNoise2DPeriodic checks that Noise2DPeriodic repeats every period along
+// both axes and runs smoothly across the seam, including with more than 256
+// lattice cells per period, where the wrapped cells are masked into the
+// permutation table.
+func TestNoise2DPeriodic(t *testing.T) {
+	tests := []struct {
+		freq, period float64
+	}{
+		{0.05, 128},
+		{0.013, 500},
+		{1, 3},
+		{0.001, 100}, // rounded up to a single cell
+		{1, 300},     // 300 cells
+		{2.5, 1000},  // 2500 cells
+	}
+	r := rand.New(rand.NewSource(4))
+	for _, g := range GradientSets {
+		p := NewPerlin(9, WithGradients(g))
+		for _, tt := range tests {
+			points := [][2]float64{
+				{0, 0},
+				// just inside the seam, where the last cell wraps to the first
+				{tt.period - 1e-3, tt.period - 1e-3},
+				{-1e-3, tt.period / 2},
+			}
+			for range 100 {
+				points = append(points, [2]float64{(r.Float64() - 0.5) * 4 * tt.period, (r.Float64() - 0.5) * 4 * tt.period})
+			}
+			for _, pt := range points {
+				x, y := pt[0], pt[1]
+				want := p.Noise2DPeriodic(x, y, tt.freq, tt.period)
+				got := p.Noise2DPeriodic(x+tt.period, y-tt.period, tt.freq, tt.period)
+				if math.Abs(got-want) > 1e-9 {
+					t.Fatalf("gradients %d, freq %v, period %v: f(%v, %v) = %v but f(x+period, y-period) = %v", g, tt.freq, tt.period, x, y, want, got)
+				}
+			}
+
+			// the noise is 0 on the lattice whatever the gradients, so a wrong
+			// wrap shows as a kink: the slope must match on both sides of the seam
+			f := math.Max(1, math.Round(tt.freq*tt.period)) / tt.period
+			h := 1e-4 / f
+			for range 20 {
+				y := r.Float64() * tt.period
+				noise := func(x float64) float64 { return p.Noise2DPeriodic(x, y, tt.freq, tt.period) }
+				noiseY := func(v float64) float64 { return p.Noise2DPeriodic(y, v, tt.freq, tt.period) }
+				for _, n := range []func(float64) float64{noise, noiseY} {
+					left := (n(tt.period) - n(tt.period-h)) / h
+					right := (n(tt.period+h) - n(tt.period)) / h
+					if math.Abs(left-right) > 1e-2*f {
+						t.Fatalf("gradients %d, freq %v, period %v: slope %v before the seam and %v after it", g, tt.freq, tt.period, left, right)
+					}
+				}
+			}
+		}
+	}
+}
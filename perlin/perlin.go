@@ -5,13 +5,15 @@ import (
 	"math/rand"
 )
 
-// Perlin holds the duplicated permutation table (512 entries).
+// Perlin holds the duplicated permutation table (512 entries) and its
+// gradient set (nil for the four diagonals).
 type Perlin struct {
-	p []int
+	p     []int
+	grads [][2]float64
 }
 
-// NewPerlin creates a Perlin instance seeded deterministically.
-func NewPerlin(seed int64) *Perlin {
+// NewPerlin creates a Perlin instance seeded deterministically, configured by opts.
+func NewPerlin(seed int64, opts ...Option) *Perlin {
 	r := rand.New(rand.NewSource(seed))
 	base := r.Perm(256)
 
@@ -20,6 +22,9 @@ func NewPerlin(seed int64) *Perlin {
 		p.p[i] = base[i]
 		p.p[256+i] = base[i]
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
 	return p
 }
 
@@ -33,8 +38,12 @@ func lerp(t, a, b float64) float64 {
 	return a + t*(b-a)
 }
 
-// grad converts a hash into one of 4 diagonal gradients and returns the dot product.
+// grad converts a hash into one of the gradients and returns the dot product.
 func (p *Perlin) grad(hash int, x, y float64) float64 {
+	if p.grads != nil {
+		g := p.grads[hash&(len(p.grads)-1)]
+		return g[0]*x + g[1]*y
+	}
 	h := hash & 3
 	switch h {
 	case 0:
@@ -50,6 +59,7 @@ func (p *Perlin) grad(hash int, x, y float64) float64 {
 
 // Noise2DRaw returns 2D Perlin noise approximately in [-1, 1].
 // x,y are world coords; freq is frequency multiplier (larger freq -> more detail).
+// Non-finite coordinates (or ones that overflow once scaled) give 0.
 func (p *Perlin) Noise2DRaw(x, y, freq float64) float64 {
 	xf := x * freq
 	yf := y * freq
@@ -59,6 +69,11 @@ func (p *Perlin) Noise2DRaw(x, y, freq float64) float64 {
 
 	xf = xf - math.Floor(xf)
 	yf = yf - math.Floor(yf)
+	// NaN, infinite or overflowing coordinates have no place in the lattice;
+	// treat them as a lattice point, where the noise is 0
+	if math.IsNaN(xf) || math.IsNaN(yf) {
+		return 0
+	}
 
 	u := fade(xf)
 	v := fade(yf)
@@ -83,22 +98,7 @@ func (p *Perlin) Noise2D(x, y, freq float64) float64 {
 // octaves is integer number of octaves; persistence < 1 reduces amplitude each octave;
 // lacunarity > 1 increases frequency each octave.
 func (p *Perlin) FBM2DRaw(x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
-	total := 0.0
-	amplitude := 1.0
-	frequency := baseFreq
-	maxAmp := 0.0
-
-	for i := 0; i < octaves; i++ {
-		total += p.Noise2DRaw(x, y, frequency) * amplitude
-		maxAmp += amplitude
-		amplitude *= persistence
-		frequency *= lacunarity
-	}
-
-	if maxAmp == 0 {
-		return 0
-	}
-	return total / maxAmp
+	return FBM(p.Noise2DRaw, x, y, baseFreq, octaves, persistence, lacunarity)
 }
 
 // FBM2D is a compatibility wrapper similar to your original FBM2D signature.
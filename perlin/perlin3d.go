@@ -0,0 +1,95 @@
+package perlin
+
+import "math"
+
+// grad3 converts a hash into one of the 12 edge gradients of a cube (from Ken
+// Perlin's improved noise) and returns the dot product with (x, y, z).
+func grad3(hash int, x, y, z float64) float64 {
+	h := hash & 15
+	u := y
+	if h < 8 {
+		u = x
+	}
+	v := z
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+// Noise3DRaw returns 3D Perlin noise approximately in [-1, 1]. Use the third
+// axis for time to animate a map, or sample points on a sphere for planets.
+// x,y,z are world coords; freq is frequency multiplier (larger freq -> more detail).
+func (p *Perlin) Noise3DRaw(x, y, z, freq float64) float64 {
+	xf := x * freq
+	yf := y * freq
+	zf := z * freq
+
+	xi := int(math.Floor(xf)) & 255
+	yi := int(math.Floor(yf)) & 255
+	zi := int(math.Floor(zf)) & 255
+
+	xf = xf - math.Floor(xf)
+	yf = yf - math.Floor(yf)
+	zf = zf - math.Floor(zf)
+
+	u := fade(xf)
+	v := fade(yf)
+	w := fade(zf)
+
+	a := p.p[xi] + yi
+	aa := p.p[a] + zi
+	ab := p.p[a+1] + zi
+	b := p.p[xi+1] + yi
+	ba := p.p[b] + zi
+	bb := p.p[b+1] + zi
+
+	x1 := lerp(u, grad3(p.p[aa], xf, yf, zf), grad3(p.p[ba], xf-1, yf, zf))
+	x2 := lerp(u, grad3(p.p[ab], xf, yf-1, zf), grad3(p.p[bb], xf-1, yf-1, zf))
+	y1 := lerp(v, x1, x2)
+
+	x1 = lerp(u, grad3(p.p[aa+1], xf, yf, zf-1), grad3(p.p[ba+1], xf-1, yf, zf-1))
+	x2 = lerp(u, grad3(p.p[ab+1], xf, yf-1, zf-1), grad3(p.p[bb+1], xf-1, yf-1, zf-1))
+	y2 := lerp(v, x1, x2)
+
+	return lerp(w, y1, y2)
+}
+
+// Noise3D returns normalized 3D Perlin noise in [0,1] (wrapper around Noise3DRaw).
+func (p *Perlin) Noise3D(x, y, z, freq float64) float64 {
+	return (p.Noise3DRaw(x, y, z, freq) + 1.0) * 0.5
+}
+
+// FBM3DRaw is the 3D counterpart of FBM2DRaw, in approx [-1,1].
+func (p *Perlin) FBM3DRaw(x, y, z, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	total := 0.0
+	amplitude := 1.0
+	frequency := baseFreq
+	maxAmp := 0.0
+
+	for i := 0; i < octaves; i++ {
+		total += p.Noise3DRaw(x, y, z, frequency) * amplitude
+		maxAmp += amplitude
+		amplitude *= persistence
+		frequency *= lacunarity
+	}
+
+	if maxAmp == 0 {
+		return 0
+	}
+	return total / maxAmp
+}
+
+// FBM3D is the 3D counterpart of FBM2D; it returns [0,1].
+func (p *Perlin) FBM3D(x, y, z, baseFreq, octaves, persistence, lacunarity float64) float64 {
+	raw := p.FBM3DRaw(x, y, z, baseFreq, int(octaves), persistence, lacunarity)
+	return (raw + 1.0) * 0.5
+}
@@ -0,0 +1,59 @@
+package perlin
+
+import (
+	"math"
+	"testing"
+)
+
+// unitRange is the bound on Noise2DRaw and FBM2DRaw, with room for rounding.
+const unitRange = 1 + 1e-9
+
+// FuzzNoise2D samples Noise2DRaw and Noise2D at any coordinates and
+// frequency, including NaN, infinities and 1e300, for every gradient set.
+// The results must stay within [-1, 1] and [0, 1].
+func FuzzNoise2D(f *testing.F) {
+	f.Add(int64(1), 12.5, -3.25, 0.05)
+	f.Add(int64(2), math.NaN(), 0.0, 1.0)
+	f.Add(int64(3), 0.0, math.NaN(), 1.0)
+	f.Add(int64(4), math.Inf(1), 5.0, 1.0)
+	f.Add(int64(5), 5.0, math.Inf(-1), 1.0)
+	f.Add(int64(6), 1e300, -1e300, 1.0)
+	f.Add(int64(7), 1e300, 1e300, 1e300)
+	f.Add(int64(8), 1.0, 1.0, math.NaN())
+	f.Add(int64(9), -1e-300, 1e-300, math.Inf(1))
+
+	f.Fuzz(func(t *testing.T, seed int64, x, y, freq float64) {
+		for _, g := range GradientSets {
+			p := NewPerlin(seed, WithGradients(g))
+			if v := p.Noise2DRaw(x, y, freq); !(math.Abs(v) <= unitRange) {
+				t.Fatalf("Noise2DRaw(%v, %v, %v) = %v", x, y, freq, v)
+			}
+			if v := p.Noise2D(x, y, freq); !(v >= -1e-9 && v <= unitRange) {
+				t.Fatalf("Noise2D(%v, %v, %v) = %v", x, y, freq, v)
+			}
+		}
+	})
+}
+
+// FuzzFBM2D sums up to 12 octaves at any coordinates, including NaN,
+// infinities and 1e300, where frequencies overflow along the way. The results
+// must stay within [-1, 1] for FBM2DRaw and [0, 1] for FBM2D.
+func FuzzFBM2D(f *testing.F) {
+	f.Add(int64(1), 100.0, 200.0, 0.01, uint8(6))
+	f.Add(int64(2), math.NaN(), math.NaN(), 0.01, uint8(6))
+	f.Add(int64(3), math.Inf(1), math.Inf(-1), 0.01, uint8(6))
+	f.Add(int64(4), 1e300, 1e300, 0.01, uint8(12))
+	f.Add(int64(5), 3.0, 4.0, 1e300, uint8(12))
+	f.Add(int64(6), 3.0, 4.0, 0.01, uint8(0))
+
+	f.Fuzz(func(t *testing.T, seed int64, x, y, freq float64, octaves uint8) {
+		p := NewPerlin(seed)
+		octs := int(octaves % 13)
+		if v := p.FBM2DRaw(x, y, freq, octs, 0.5, 2); !(math.Abs(v) <= unitRange) {
+			t.Fatalf("FBM2DRaw(%v, %v, %v, %d) = %v", x, y, freq, octs, v)
+		}
+		if v := p.FBM2D(x, y, freq, float64(octs), 0.5, 2); !(v >= -1e-9 && v <= unitRange) {
+			t.Fatalf("FBM2D(%v, %v, %v, %d) = %v", x, y, freq, octs, v)
+		}
+	})
+}
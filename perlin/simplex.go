@@ -0,0 +1,80 @@
+package perlin
+
+import "math"
+
+// Skewing factors for 2D simplex noise.
+var (
+	f2 = 0.5 * (math.Sqrt(3) - 1)
+	g2 = (3 - math.Sqrt(3)) / 6
+)
+
+// simplexGrads are the 8 gradient directions used by Simplex2DRaw.
+var simplexGrads = [8][2]float64{
+	{1, 1}, {-1, 1}, {1, -1}, {-1, -1},
+	{1, 0}, {-1, 0}, {0, 1}, {0, -1},
+}
+
+// simplexCorner returns the contribution of one simplex corner at offset (x, y).
+func (p *Perlin) simplexCorner(hash int, x, y float64) float64 {
+	t := 0.5 - x*x - y*y
+	if t < 0 {
+		return 0
+	}
+	g := simplexGrads[hash&7]
+	t *= t
+	return t * t * (g[0]*x + g[1]*y)
+}
+
+// Simplex2DRaw returns 2D simplex noise approximately in [-1, 1].
+// It shares the permutation table of the Perlin instance, so the same seed gives
+// related but different terrain. Simplex noise has no axis-aligned artifacts.
+// x,y are world coords; freq is frequency multiplier (larger freq -> more detail).
+func (p *Perlin) Simplex2DRaw(x, y, freq float64) float64 {
+	xf := x * freq
+	yf := y * freq
+
+	// skew the input space to find the simplex cell
+	s := (xf + yf) * f2
+	i := math.Floor(xf + s)
+	j := math.Floor(yf + s)
+	t := (i + j) * g2
+	x0 := xf - (i - t)
+	y0 := yf - (j - t)
+
+	// which of the two triangles of the cell we are in
+	i1, j1 := 0, 1
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+
+	x1 := x0 - float64(i1) + g2
+	y1 := y0 - float64(j1) + g2
+	x2 := x0 - 1 + 2*g2
+	y2 := y0 - 1 + 2*g2
+
+	ii := int(i) & 255
+	jj := int(j) & 255
+
+	n0 := p.simplexCorner(p.p[ii+p.p[jj]], x0, y0)
+	n1 := p.simplexCorner(p.p[ii+i1+p.p[jj+j1]], x1, y1)
+	n2 := p.simplexCorner(p.p[ii+1+p.p[jj+1]], x2, y2)
+
+	// scale to roughly [-1,1]
+	return 70 * (n0 + n1 + n2)
+}
+
+// Simplex2D returns normalized simplex noise in [0,1] (wrapper around Simplex2DRaw).
+func (p *Perlin) Simplex2D(x, y, freq float64) float64 {
+	return (p.Simplex2DRaw(x, y, freq) + 1.0) * 0.5
+}
+
+// SimplexFBM2DRaw is FBM2DRaw built on Simplex2DRaw instead of Noise2DRaw.
+func (p *Perlin) SimplexFBM2DRaw(x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	return FBM(p.Simplex2DRaw, x, y, baseFreq, octaves, persistence, lacunarity)
+}
+
+// SimplexFBM2D is FBM2D built on Simplex2DRaw; it returns [0,1].
+func (p *Perlin) SimplexFBM2D(x, y, baseFreq, octaves, persistence, lacunarity float64) float64 {
+	raw := p.SimplexFBM2DRaw(x, y, baseFreq, int(octaves), persistence, lacunarity)
+	return (raw + 1.0) * 0.5
+}
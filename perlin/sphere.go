@@ -0,0 +1,35 @@
+package perlin
+
+import "math"
+
+// SpherePoint returns the point at longitude lon and latitude lat (radians)
+// on a sphere of the given radius centered on the origin, with the poles on
+// the z axis.
+func SpherePoint(lon, lat, radius float64) (x, y, z float64) {
+	c := math.Cos(lat)
+	return radius * c * math.Cos(lon), radius * c * math.Sin(lon), radius * math.Sin(lat)
+}
+
+// SphereNoise returns 3D noise sampled on the unit sphere at longitude lon and
+// latitude lat (radians), approximately in [-1, 1]. freq is in cycles per
+// sphere radius. Sampling the sphere itself rather than a flat map avoids
+// both a seam at ±180° and pinching at the poles.
+func (p *Perlin) SphereNoise(lon, lat, freq float64) float64 {
+	x, y, z := SpherePoint(lon, lat, 1)
+	return p.Noise3DRaw(x, y, z, freq)
+}
+
+// Equirectangular returns a NoiseFunc over the pixel coordinates of a
+// width x height equirectangular map: x spans longitude -180° to 180° and y
+// latitude 90° to -90°. It samples Noise3DRaw on a sphere whose equator is
+// width pixels long, so freq keeps its per-pixel meaning at the equator and
+// the fractal helpers (FBM, Ridged, ...) can build whole planets from it.
+func (p *Perlin) Equirectangular(width, height int) NoiseFunc {
+	radius := float64(width) / (2 * math.Pi)
+	return func(x, y, freq float64) float64 {
+		lon := (x+0.5)/float64(width)*2*math.Pi - math.Pi
+		lat := math.Pi/2 - (y+0.5)/float64(height)*math.Pi
+		sx, sy, sz := SpherePoint(lon, lat, radius)
+		return p.Noise3DRaw(sx, sy, sz, freq)
+	}
+}
@@ -0,0 +1,98 @@
+package perlin
+
+import "math"
+
+// hashToValue maps a permutation table entry to [-1,1].
+func hashToValue(h int) float64 {
+	return float64(h)*(2.0/255) - 1
+}
+
+// Value2DRaw returns 2D value noise approximately in [-1, 1]: random values at
+// the lattice points, blended with the Perlin fade curve. It is cheaper than
+// gradient noise but blockier, which matters little at low frequencies.
+// x,y are world coords; freq is frequency multiplier (larger freq -> more detail).
+func (p *Perlin) Value2DRaw(x, y, freq float64) float64 {
+	xf := x * freq
+	yf := y * freq
+
+	x0 := math.Floor(xf)
+	y0 := math.Floor(yf)
+	xi := int(x0) & 255
+	yi := int(y0) & 255
+
+	u := fade(xf - x0)
+	v := fade(yf - y0)
+
+	a := p.p[xi]
+	b := p.p[xi+1]
+	top := lerp(u, hashToValue(p.p[a+yi]), hashToValue(p.p[b+yi]))
+	bottom := lerp(u, hashToValue(p.p[a+yi+1]), hashToValue(p.p[b+yi+1]))
+	return lerp(v, top, bottom)
+}
+
+// Value2D returns normalized value noise in [0,1] (wrapper around Value2DRaw).
+func (p *Perlin) Value2D(x, y, freq float64) float64 {
+	return (p.Value2DRaw(x, y, freq) + 1.0) * 0.5
+}
+
+// ValueFBM2DRaw is FBM2DRaw built on Value2DRaw instead of Noise2DRaw.
+func (p *Perlin) ValueFBM2DRaw(x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	return FBM(p.Value2DRaw, x, y, baseFreq, octaves, persistence, lacunarity)
+}
+
+// ValueFBM2D is FBM2D built on Value2DRaw; it returns [0,1].
+func (p *Perlin) ValueFBM2D(x, y, baseFreq, octaves, persistence, lacunarity float64) float64 {
+	raw := p.ValueFBM2DRaw(x, y, baseFreq, int(octaves), persistence, lacunarity)
+	return (raw + 1.0) * 0.5
+}
+
+// cubic interpolates between b and c with the Catmull-Rom spline through a, b, c, d.
+func cubic(t, a, b, c, d float64) float64 {
+	return b + 0.5*t*(c-a+t*(2*a-5*b+4*c-d+t*(3*(b-c)+d-a)))
+}
+
+// Cubic2DRaw returns 2D value noise with bicubic (Catmull-Rom) interpolation
+// over a 4x4 neighborhood of lattice values. It is smoother than Value2DRaw
+// but reads four times as many lattice values. The spline can overshoot, so
+// values slightly beyond [-1, 1] are possible.
+// x,y are world coords; freq is frequency multiplier (larger freq -> more detail).
+func (p *Perlin) Cubic2DRaw(x, y, freq float64) float64 {
+	xf := x * freq
+	yf := y * freq
+
+	x0 := math.Floor(xf)
+	y0 := math.Floor(yf)
+	xi, yi := int(x0), int(y0)
+	u := xf - x0
+	v := yf - y0
+
+	// column hashes are shared by all four rows
+	var cols [4]int
+	for i := range cols {
+		cols[i] = p.p[(xi+i-1)&255]
+	}
+	var rows [4]float64
+	for j := range rows {
+		yy := (yi + j - 1) & 255
+		rows[j] = cubic(u,
+			hashToValue(p.p[cols[0]+yy]), hashToValue(p.p[cols[1]+yy]),
+			hashToValue(p.p[cols[2]+yy]), hashToValue(p.p[cols[3]+yy]))
+	}
+	return cubic(v, rows[0], rows[1], rows[2], rows[3])
+}
+
+// Cubic2D returns normalized cubic value noise in approx [0,1] (wrapper around Cubic2DRaw).
+func (p *Perlin) Cubic2D(x, y, freq float64) float64 {
+	return (p.Cubic2DRaw(x, y, freq) + 1.0) * 0.5
+}
+
+// CubicFBM2DRaw is FBM2DRaw built on Cubic2DRaw instead of Noise2DRaw.
+func (p *Perlin) CubicFBM2DRaw(x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+	return FBM(p.Cubic2DRaw, x, y, baseFreq, octaves, persistence, lacunarity)
+}
+
+// CubicFBM2D is FBM2D built on Cubic2DRaw; it returns approx [0,1].
+func (p *Perlin) CubicFBM2D(x, y, baseFreq, octaves, persistence, lacunarity float64) float64 {
+	raw := p.CubicFBM2DRaw(x, y, baseFreq, int(octaves), persistence, lacunarity)
+	return (raw + 1.0) * 0.5
+}
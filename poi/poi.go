@@ -1,10 +1,24 @@
 package poi
 
 import (
+	"errors"
 	"math"
 	"math/rand"
+
+	"perlin_noise/heightfield"
+)
+
+var (
+	// ErrInvalidParams is returned by PoissonDisk for a non-positive spacing or map size.
+	ErrInvalidParams = errors.New("poi: minDistance, width and height must be positive")
+	// ErrNoLand is returned by PoissonDisk when no cell rises LandMargin above sea level to hold a point.
+	ErrNoLand = errors.New("poi: no land 0.05 or more above sea level to place points on")
 )
 
+// LandMargin is how far above sea level a cell must be to hold a point, so
+// points stand inland rather than on the waterline.
+const LandMargin = 0.05
+
 // Point represents a 2D point with integer coordinates.
 type Point struct {
 	X int
@@ -12,37 +26,51 @@ type Point struct {
 }
 
 // PoissonDisk generates a set of points that are at least minDistance from each other.
-// It returns a slice of points and the number of points generated.
+// It returns a slice of points and the number of points generated, or ErrNoLand
+// if the map has no land to start from.
 // This implementation is a variation of Bridson's algorithm.
-func PoissonDisk(minDistance, width, height int64, r *rand.Rand, noiseMap map[Point]float64, seaLevel float64) ([]Point, int) {
+func PoissonDisk(minDistance, width, height int64, r *rand.Rand, noiseMap *heightfield.Field, seaLevel float64) ([]Point, int, error) {
+	return PoissonDiskWeighted(minDistance, width, height, r, noiseMap, seaLevel, nil)
+}
+
+// PoissonDiskWeighted is PoissonDisk with a desirability in [0,1] for every
+// cell, row-major: a candidate point that fits is kept with the desirability
+// of its cell as probability, so points gather where it is high and thin out
+// where it is low. The first point is placed anywhere on land. A nil
+// desirability keeps every candidate, as PoissonDisk does.
+func PoissonDiskWeighted(minDistance, width, height int64, r *rand.Rand, noiseMap *heightfield.Field, seaLevel float64, desirability []float64) ([]Point, int, error) {
 	
+	// Nothing can be placed on an empty map or with a non-positive spacing
+	if minDistance <= 0 || width <= 0 || height <= 0 {
+		return nil, 0, ErrInvalidParams
+	}
+
 	// Data structures for the algorithm
 	var points []Point
 	var activePoints []Point
 	
-	// We use a grid to speed up the distance checks.
+	// We use a grid to speed up the distance checks. Each cell holds the
+	// 1-based index into points of the point inside it, 0 while empty (the
+	// zero Point cannot mark empty cells: (0, 0) is a valid point).
 	cellSize := float64(minDistance) / math.Sqrt2
 	gridWidth := int(math.Ceil(float64(width) / cellSize))
 	gridHeight := int(math.Ceil(float64(height) / cellSize))
-	grid := make([][]Point, gridWidth)
+	grid := make([][]int, gridWidth)
 	for i := range grid {
-		grid[i] = make([]Point, gridHeight)
+		grid[i] = make([]int, gridHeight)
 	}
 	
 	// Add an initial random point on land
-	var startPoint Point
-	for {
-		startPoint = Point{X: r.Intn(int(width)), Y: r.Intn(int(height))}
-		if noiseMap[startPoint] >= seaLevel+0.05 {
-			break
-		}
+	startPoint, ok := startOnLand(width, height, r, noiseMap, seaLevel)
+	if !ok {
+		return nil, 0, ErrNoLand
 	}
 	
 	points = append(points, startPoint)
 	activePoints = append(activePoints, startPoint)
 	gridX := int(float64(startPoint.X) / cellSize)
 	gridY := int(float64(startPoint.Y) / cellSize)
-	grid[gridX][gridY] = startPoint
+	grid[gridX][gridY] = len(points)
 
 	for len(activePoints) > 0 {
 		randomIndex := r.Intn(len(activePoints))
@@ -61,7 +89,7 @@ func PoissonDisk(minDistance, width, height int64, r *rand.Rand, noiseMap map[Po
 			}
 			
 			// Check if the new point is within the bounds and on land
-			if newPoint.X >= 0 && newPoint.X < int(width) && newPoint.Y >= 0 && newPoint.Y < int(height) && noiseMap[newPoint] >= seaLevel+0.05 {
+			if newPoint.X >= 0 && newPoint.X < int(width) && newPoint.Y >= 0 && newPoint.Y < int(height) && noiseMap.At(newPoint.X, newPoint.Y) >= seaLevel+LandMargin {
 				
 				// Check if the candidate is far enough from existing points
 				gridX = int(float64(newPoint.X) / cellSize)
@@ -70,8 +98,9 @@ func PoissonDisk(minDistance, width, height int64, r *rand.Rand, noiseMap map[Po
 				ok := true
 				for x := gridX - 2; x <= gridX+2; x++ {
 					for y := gridY - 2; y <= gridY+2; y++ {
-						if x >= 0 && x < gridWidth && y >= 0 && y < gridHeight && grid[x][y] != (Point{}) {
-							dist := math.Sqrt(math.Pow(float64(newPoint.X-grid[x][y].X), 2) + math.Pow(float64(newPoint.Y-grid[x][y].Y), 2))
+						if x >= 0 && x < gridWidth && y >= 0 && y < gridHeight && grid[x][y] != 0 {
+							other := points[grid[x][y]-1]
+							dist := math.Sqrt(math.Pow(float64(newPoint.X-other.X), 2) + math.Pow(float64(newPoint.Y-other.Y), 2))
 							if dist < float64(minDistance) {
 								ok = false
 							}
@@ -79,10 +108,13 @@ func PoissonDisk(minDistance, width, height int64, r *rand.Rand, noiseMap map[Po
 					}
 				}
 				
+				if ok && desirability != nil && r.Float64() >= desirability[newPoint.Y*int(width)+newPoint.X] {
+					ok = false
+				}
 				if ok {
 					points = append(points, newPoint)
 					activePoints = append(activePoints, newPoint)
-					grid[gridX][gridY] = newPoint
+					grid[gridX][gridY] = len(points)
 					foundCandidate = true
 					break
 				}
@@ -94,5 +126,46 @@ func PoissonDisk(minDistance, width, height int64, r *rand.Rand, noiseMap map[Po
 		}
 	}
 	
-	return points, len(points)
+	return points, len(points), nil
+}
+
+// startAttempts is how many random picks startOnLand makes before scanning the map.
+const startAttempts = 1000
+
+// startOnLand picks a random point on land (at least seaLevel+LandMargin).
+// Random picks are tried first; if they all land in water (e.g. a world that is
+// mostly sea), one of the land points is chosen from a full scan instead.
+// It reports false if the map has no land at all.
+func startOnLand(width, height int64, r *rand.Rand, noiseMap *heightfield.Field, seaLevel float64) (Point, bool) {
+	for i := 0; i < startAttempts; i++ {
+		p := Point{X: r.Intn(int(width)), Y: r.Intn(int(height))}
+		if noiseMap.At(p.X, p.Y) >= seaLevel+LandMargin {
+			return p, true
+		}
+	}
+
+	var land []Point
+	for y := 0; y < int(height); y++ {
+		for x := 0; x < int(width); x++ {
+			p := Point{X: x, Y: y}
+			if noiseMap.At(p.X, p.Y) >= seaLevel+LandMargin {
+				land = append(land, p)
+			}
+		}
+	}
+	if len(land) == 0 {
+		return Point{}, false
+	}
+	return land[r.Intn(len(land))], true
+}
+
+// Submerged returns the points whose noise value lies below seaLevel.
+func Submerged(points []Point, noiseMap *heightfield.Field, seaLevel float64) []Point {
+	var submerged []Point
+	for _, p := range points {
+		if noiseMap.At(p.X, p.Y) < seaLevel {
+			submerged = append(submerged, p)
+		}
+	}
+	return submerged
 }
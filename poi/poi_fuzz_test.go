@@ -0,0 +1,77 @@
+package poi
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"perlin_noise/heightfield"
+)
+
+// FuzzPoissonDisk runs PoissonDisk on random fields of up to 64x64 cells with
+// any spacing and sea level. It must return, without panicking, either an
+// error or points that are on the map, on land and minDistance apart.
+func FuzzPoissonDisk(f *testing.F) {
+	f.Add(int64(5), uint8(64), uint8(64), 0.5, int64(1), false)
+	f.Add(int64(0), uint8(32), uint8(32), 0.5, int64(2), false)
+	f.Add(int64(-3), uint8(32), uint8(32), 0.5, int64(3), false)
+	f.Add(int64(math.MaxInt64), uint8(64), uint8(64), 0.5, int64(4), false)
+	f.Add(int64(1)<<40, uint8(16), uint8(16), 0.2, int64(5), false)
+	f.Add(int64(3), uint8(64), uint8(64), 0.5, int64(6), true)  // all water
+	f.Add(int64(3), uint8(64), uint8(64), 2.0, int64(7), false) // sea above every cell
+	f.Add(int64(1), uint8(1), uint8(1), 0.0, int64(8), false)   // 1x1 map
+	f.Add(int64(1), uint8(1), uint8(1), 0.0, int64(9), true)    // 1x1 water
+	f.Add(int64(1), uint8(0), uint8(5), 0.0, int64(10), false)  // empty map
+	f.Add(int64(2), uint8(64), uint8(1), math.NaN(), int64(11), false)
+	f.Add(int64(2), uint8(8), uint8(8), math.Inf(-1), int64(12), false)
+
+	f.Fuzz(func(t *testing.T, minDistance int64, w, h uint8, seaLevel float64, seed int64, water bool) {
+		width, height := int(w)%65, int(h)%65
+		field := heightfield.New(width, height)
+		r := rand.New(rand.NewSource(seed))
+		for i := range field.Data {
+			if water {
+				field.Data[i] = seaLevel - 1
+			} else {
+				field.Data[i] = r.Float64()
+			}
+		}
+
+		points, n, err := PoissonDisk(minDistance, int64(width), int64(height), r, field, seaLevel)
+		if minDistance <= 0 || width == 0 || height == 0 {
+			if !errors.Is(err, ErrInvalidParams) {
+				t.Fatalf("err = %v, want ErrInvalidParams", err)
+			}
+			return
+		}
+		if err != nil {
+			if !errors.Is(err, ErrNoLand) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			for _, v := range field.Data {
+				if v >= seaLevel+LandMargin {
+					t.Fatalf("ErrNoLand on a map with a cell at %v, sea level %v", v, seaLevel)
+				}
+			}
+			return
+		}
+
+		if n != len(points) || n == 0 {
+			t.Fatalf("n = %d for %d points", n, len(points))
+		}
+		for i, p := range points {
+			if p.X < 0 || p.X >= width || p.Y < 0 || p.Y >= height {
+				t.Fatalf("point %v outside the %dx%d map", p, width, height)
+			}
+			if v := field.At(p.X, p.Y); !(v >= seaLevel+LandMargin) {
+				t.Fatalf("point %v at height %v, sea level %v", p, v, seaLevel)
+			}
+			for _, q := range points[:i] {
+				if d := math.Hypot(float64(p.X-q.X), float64(p.Y-q.Y)); d < float64(minDistance) {
+					t.Fatalf("points %v and %v are %v apart, want at least %d", p, q, d, minDistance)
+				}
+			}
+		}
+	})
+}
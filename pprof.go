@@ -0,0 +1,18 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	_ "net/http/pprof" // registers the /debug/pprof handlers
+)
+
+// startPprof serves the net/http/pprof endpoints on addr in the background,
+// so slow generations can be profiled with `go tool pprof`.
+func startPprof(addr string) {
+	go func() {
+		log.Printf("pprof listening on http://%s/debug/pprof/", addr)
+		if err := http.ListenAndServe(addr, nil); err != nil {
+			log.Println("pprof error:", err)
+		}
+	}()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"perlin_noise/modules"
+)
+
+// builtInRecipe is the recipe option that builds the terrain from the sliders.
+const builtInRecipe = "built-in"
+
+// recipeOptions lists the built-in terrain followed by the recipe files in
+// the working directory, where saved recipes go.
+func recipeOptions() []string {
+	files, _ := filepath.Glob("*" + modules.RecipeExt)
+	return append([]string{builtInRecipe}, files...)
+}
+
+// loadRecipe reads the recipe file at path.
+func loadRecipe(path string) (*modules.Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return modules.ReadRecipe(f)
+}
+
+// saveRecipe writes recipe to path.
+func saveRecipe(path string, recipe *modules.Node) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := modules.WriteRecipe(f, recipe); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
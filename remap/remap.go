@@ -0,0 +1,108 @@
+// Package remap reshapes normalized height values in [0,1]: terracing, power
+// redistribution and free-form curves through control points.
+package remap
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Power redistributes v in [0,1] by raising it to exponent. Exponents above 1
+// flatten the low end and sharpen the high end; below 1 the reverse.
+func Power(v, exponent float64) float64 {
+	if v <= 0 {
+		return 0
+	}
+	return math.Pow(v, exponent)
+}
+
+// Terrace cuts v in [0,1] into steps flat terraces joined by slopes.
+// sharpness in [0,1] sets how flat the terraces are: 0 leaves v unchanged and
+// 1 gives hard steps with vertical cliffs. steps < 1 leaves v unchanged.
+func Terrace(v float64, steps int, sharpness float64) float64 {
+	if steps < 1 || sharpness <= 0 {
+		return v
+	}
+	s := v * float64(steps)
+	k := math.Floor(s)
+	f := s - k
+	if sharpness >= 1 {
+		f = 0
+	} else {
+		// each terrace stays flat for most of its width, then climbs to the next
+		f = math.Pow(f, 1/(1-sharpness))
+	}
+	return (k + f) / float64(steps)
+}
+
+// Curve is a smooth, monotone mapping through a set of control points.
+// Between the points it interpolates with a monotone cubic (Fritsch–Carlson),
+// so it never overshoots them; outside them it holds the end values.
+type Curve struct {
+	xs, ys, ms []float64
+}
+
+// NewCurve builds a curve through the control points, given as (x, y) pairs
+// in any order. It needs at least two points with distinct x values.
+func NewCurve(points ...[2]float64) (*Curve, error) {
+	if len(points) < 2 {
+		return nil, errors.New("remap: a curve needs at least two control points")
+	}
+	pts := append([][2]float64(nil), points...)
+	sort.Slice(pts, func(i, j int) bool { return pts[i][0] < pts[j][0] })
+
+	n := len(pts)
+	c := &Curve{xs: make([]float64, n), ys: make([]float64, n), ms: make([]float64, n)}
+	for i, p := range pts {
+		c.xs[i], c.ys[i] = p[0], p[1]
+		if i > 0 && c.xs[i] == c.xs[i-1] {
+			return nil, errors.New("remap: curve control points must have distinct x values")
+		}
+	}
+
+	// secant slopes, then tangents limited so each segment stays monotone
+	d := make([]float64, n-1)
+	for i := range d {
+		d[i] = (c.ys[i+1] - c.ys[i]) / (c.xs[i+1] - c.xs[i])
+	}
+	c.ms[0], c.ms[n-1] = d[0], d[n-2]
+	for i := 1; i < n-1; i++ {
+		if d[i-1]*d[i] <= 0 {
+			c.ms[i] = 0
+		} else {
+			c.ms[i] = (d[i-1] + d[i]) / 2
+		}
+	}
+	for i := range d {
+		if d[i] == 0 {
+			c.ms[i], c.ms[i+1] = 0, 0
+			continue
+		}
+		a, b := c.ms[i]/d[i], c.ms[i+1]/d[i]
+		if h := a*a + b*b; h > 9 {
+			t := 3 / math.Sqrt(h)
+			c.ms[i] = t * a * d[i]
+			c.ms[i+1] = t * b * d[i]
+		}
+	}
+	return c, nil
+}
+
+// At returns the curve's value at v.
+func (c *Curve) At(v float64) float64 {
+	n := len(c.xs)
+	if v <= c.xs[0] {
+		return c.ys[0]
+	}
+	if v >= c.xs[n-1] {
+		return c.ys[n-1]
+	}
+	i := sort.SearchFloat64s(c.xs, v) - 1
+	h := c.xs[i+1] - c.xs[i]
+	t := (v - c.xs[i]) / h
+	t2, t3 := t*t, t*t*t
+	// cubic Hermite basis
+	return (2*t3-3*t2+1)*c.ys[i] + (t3-2*t2+t)*h*c.ms[i] +
+		(-2*t3+3*t2)*c.ys[i+1] + (t3-t2)*h*c.ms[i+1]
+}
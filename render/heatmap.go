@@ -0,0 +1,40 @@
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+// heatmapStops is the heatmap color ramp, from 0 to the largest value.
+var heatmapStops = []color.RGBA{
+	{R: 20, G: 12, B: 60, A: 255},
+	{R: 120, G: 28, B: 110, A: 255},
+	{R: 220, G: 70, B: 50, A: 255},
+	{R: 250, G: 190, B: 40, A: 255},
+	{R: 252, G: 250, B: 180, A: 255},
+}
+
+// Heatmap draws values, a row-major width x height grid, on a dark-to-bright
+// color ramp running from 0 to the largest value. Negative values are drawn
+// as 0.
+func Heatmap(values []float64, width, height int) *image.RGBA {
+	hi := 0.0
+	for _, v := range values {
+		hi = max(hi, v)
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			t := 0.0
+			if hi > 0 {
+				t = clamp01(values[y*width+x] / hi)
+			}
+			// pick the ramp segment and blend across it
+			f := t * float64(len(heatmapStops)-1)
+			i := min(int(f), len(heatmapStops)-2)
+			out.SetRGBA(x, y, mix(heatmapStops[i], heatmapStops[i+1], f-float64(i)))
+		}
+	}
+	return out
+}
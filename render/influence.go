@@ -0,0 +1,95 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"perlin_noise/world"
+)
+
+// influenceColors tell neighboring POIs apart; POI i uses color i modulo the count.
+var influenceColors = []color.RGBA{
+	{R: 230, G: 60, B: 60, A: 255},
+	{R: 60, G: 110, B: 230, A: 255},
+	{R: 240, G: 190, B: 40, A: 255},
+	{R: 150, G: 70, B: 200, A: 255},
+	{R: 40, G: 190, B: 170, A: 255},
+	{R: 240, G: 120, B: 30, A: 255},
+}
+
+// influenceFill is the alpha inside an influence circle; its rim is opaque.
+const influenceFill = 0.3
+
+// influenceRadius returns the influence radius in pixels of POI i.
+func influenceRadius(w *world.World, opts Options, i int) float64 {
+	r := opts.InfluenceRadius
+	if r <= 0 {
+		r = float64(w.Params.MinDistance)
+	}
+	if i < len(opts.InfluenceWeights) {
+		r *= opts.InfluenceWeights[i]
+	}
+	return r
+}
+
+// influenceLayer draws the area each POI controls on a transparent image:
+// translucent circles, which build up where they overlap, or with
+// opts.InfluenceFields blended fields that fade out towards the radius,
+// each pixel going to the POI with the strongest pull.
+func influenceLayer(w *world.World, opts Options) *image.RGBA {
+	width, height := w.Params.Width, w.Params.Height
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// straight colors and alpha, composited into out at the end
+	rgb := make([][3]float64, width*height)
+	alpha := make([]float64, width*height)
+	for i, pnt := range w.POIs {
+		r := influenceRadius(w, opts, i)
+		if r <= 0 {
+			continue
+		}
+		c := influenceColors[i%len(influenceColors)]
+		src := [3]float64{float64(c.R), float64(c.G), float64(c.B)}
+		x0, x1 := max(pnt.X-int(r), 0), min(pnt.X+int(r), width-1)
+		y0, y1 := max(pnt.Y-int(r), 0), min(pnt.Y+int(r), height-1)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				d := math.Hypot(float64(x-pnt.X), float64(y-pnt.Y))
+				if d > r {
+					continue
+				}
+				j := y*width + x
+				if opts.InfluenceFields {
+					// the stronger pull owns the pixel
+					if a := (1 - d/r) * (1 - d/r); a > alpha[j] {
+						rgb[j], alpha[j] = src, a
+					}
+					continue
+				}
+				a := influenceFill
+				if d > r-1.5 {
+					a = 1
+				}
+				// "over" compositing, so overlaps read as denser
+				total := a + alpha[j]*(1-a)
+				for k := range src {
+					rgb[j][k] = (src[k]*a + rgb[j][k]*alpha[j]*(1-a)) / total
+				}
+				alpha[j] = total
+			}
+		}
+	}
+
+	for j, a := range alpha {
+		if a <= 0 {
+			continue
+		}
+		// image.RGBA is alpha-premultiplied
+		out.Pix[j*4] = uint8(rgb[j][0]*a + 0.5)
+		out.Pix[j*4+1] = uint8(rgb[j][1]*a + 0.5)
+		out.Pix[j*4+2] = uint8(rgb[j][2]*a + 0.5)
+		out.Pix[j*4+3] = uint8(a*255 + 0.5)
+	}
+	return out
+}
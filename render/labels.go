@@ -0,0 +1,107 @@
+package render
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"perlin_noise/world"
+)
+
+// Label is a name drawn on the map, centered on (X, Y). A peak label gets a
+// triangle marker at (X, Y) and its text just below it.
+type Label struct {
+	Text string
+	X, Y int
+	Peak bool
+}
+
+var (
+	labelColor     = color.RGBA{R: 30, G: 20, B: 10, A: 255}
+	labelHaloColor = color.RGBA{R: 255, G: 250, B: 235, A: 255}
+)
+
+// labelShifts are the vertical moves, in text lines, tried in turn to keep a
+// label clear of those drawn before it.
+var labelShifts = []int{0, -1, 1, -2, 2}
+
+// labelsLayer draws opts.Labels on a transparent image, in dark text with a
+// light halo so they read on any terrain. Labels are kept inside the map. A
+// label that would overlap an earlier one is moved up or down a line or two,
+// and left out if that does not clear it, so list the most important first.
+func labelsLayer(w *world.World, opts Options) *image.RGBA {
+	width, height := w.Params.Width, w.Params.Height
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	face := basicfont.Face7x13
+	ascent, descent := face.Metrics().Ascent.Ceil(), face.Metrics().Descent.Ceil()
+	lineHeight := ascent + descent + 2
+
+	var placed []image.Rectangle
+	for _, l := range opts.Labels {
+		if l.Peak {
+			peakMarker(out, l.X, l.Y)
+			placed = append(placed, image.Rect(l.X-4, l.Y-4, l.X+5, l.Y+3))
+		}
+		textW := font.MeasureString(face, l.Text).Ceil()
+		x := min(max(l.X-textW/2, 1), width-textW-1)
+		// the baseline centers the text on the point, or puts it below a peak marker
+		base := l.Y + ascent/2
+		if l.Peak {
+			base = l.Y + 5 + ascent
+		}
+
+		y, clear := 0, false
+		for _, shift := range labelShifts {
+			y = min(max(base+shift*lineHeight, ascent+1), height-descent-1)
+			box := image.Rect(x-1, y-ascent-1, x+textW+1, y+descent+1)
+			if !overlapsAny(box, placed) {
+				placed = append(placed, box)
+				clear = true
+				break
+			}
+		}
+		if !clear {
+			continue
+		}
+
+		d := font.Drawer{Dst: out, Face: face, Src: image.NewUniform(labelHaloColor)}
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				d.Dot = fixed.P(x+dx, y+dy)
+				d.DrawString(l.Text)
+			}
+		}
+		d.Src = image.NewUniform(labelColor)
+		d.Dot = fixed.P(x, y)
+		d.DrawString(l.Text)
+	}
+	return out
+}
+
+// peakMarker draws a small outlined triangle with its apex at (x, y-3).
+func peakMarker(out *image.RGBA, x, y int) {
+	for row := -1; row <= 5; row++ {
+		for dx := -row - 1; dx <= row+1; dx++ {
+			c := labelHaloColor
+			if row >= 0 && row < 5 && dx > -row-1 && dx < row+1 {
+				c = labelColor
+			}
+			if image.Pt(x+dx, y-3+row).In(out.Rect) {
+				out.SetRGBA(x+dx, y-3+row, c)
+			}
+		}
+	}
+}
+
+// overlapsAny reports whether r overlaps any of rects.
+func overlapsAny(r image.Rectangle, rects []image.Rectangle) bool {
+	for _, o := range rects {
+		if r.Overlaps(o) {
+			return true
+		}
+	}
+	return false
+}
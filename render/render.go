@@ -0,0 +1,386 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"perlin_noise/perlin"
+	"perlin_noise/poi"
+	"perlin_noise/shading"
+	"perlin_noise/world"
+)
+
+var (
+	deepWaterColor    = color.RGBA{R: 25, G: 70, B: 120, A: 255}
+	waterColor        = color.RGBA{R: 50, G: 150, B: 200, A: 255}
+	shoreColor        = color.RGBA{R: 240, G: 230, B: 140, A: 255}
+	landColor         = color.RGBA{R: 80, G: 180, B: 80, A: 255}
+	mountainColor     = color.RGBA{R: 120, G: 100, B: 80, A: 255}
+	highMountainColor = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	highLandColor     = color.RGBA{R: 100, G: 150, B: 100, A: 255}
+	poiColor          = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	floodedColor      = color.RGBA{R: 70, G: 110, B: 130, A: 255}
+	newlyFloodedColor = color.RGBA{R: 0, G: 220, B: 255, A: 255}
+	submergedPoiColor = color.RGBA{R: 40, G: 40, B: 40, A: 255}
+
+	glintColor = color.RGBA{R: 255, G: 255, B: 240, A: 255}
+	foamColor  = color.RGBA{R: 235, G: 245, B: 250, A: 255}
+
+	// highContrastColors replace the band colors in high-contrast mode: every
+	// band differs clearly in lightness from its neighbors, and land and water
+	// stay apart without relying on red and green.
+	highContrastColors = map[color.RGBA]color.RGBA{
+		deepWaterColor:    {R: 0, G: 0, B: 70, A: 255},
+		waterColor:        {R: 0, G: 90, B: 255, A: 255},
+		shoreColor:        {R: 255, G: 235, B: 0, A: 255},
+		landColor:         {R: 0, G: 170, B: 90, A: 255},
+		highLandColor:     {R: 0, G: 95, B: 45, A: 255},
+		mountainColor:     {R: 150, G: 80, B: 0, A: 255},
+		highMountainColor: {R: 255, G: 255, B: 255, A: 255},
+	}
+	highContrastPoiColor = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+)
+
+// blur radii (in pixels) sampled by the ambient occlusion pass
+var aoRadii = []int{2, 6, 16}
+
+// aoGain maps Options.AOStrength to a visible darkening; raw occlusion
+// of typical valleys is only a few hundredths of the height range
+const aoGain = 20.0
+
+// width in pixels of the coastal foam line
+const foamWidth = 4
+
+// Options controls how a world is drawn. None of them affect generation.
+type Options struct {
+	// DetailIntensity scales the per-band surface texture (0 = flat colors).
+	DetailIntensity float64
+	// AOStrength scales the valley darkening (0 = off).
+	AOStrength float64
+
+	WaterGlint  bool
+	CoastalFoam bool
+
+	// FloodRise raises the drawn sea level above the world's SeaLevel;
+	// land drowned in the last FloodStep of the rise is highlighted.
+	FloodRise float64
+	FloodStep float64
+
+	// HighContrast draws the terrain bands and POIs in a high-contrast palette
+	// that stays legible with low vision or color blindness.
+	HighContrast bool
+
+	// GridSpacing is the distance in pixels between grid lines (0 = 64).
+	GridSpacing int
+
+	// InfluenceRadius is the radius in pixels of the area each POI controls
+	// (0 = the world's MinDistance). InfluenceWeights, if set, scales it per
+	// POI, in the order of World.POIs, e.g. by settlement type or population.
+	InfluenceRadius  float64
+	InfluenceWeights []float64
+	// InfluenceFields draws fading fields instead of circles.
+	InfluenceFields bool
+
+	// Labels are the names drawn by the labels layer, such as mountain
+	// ranges and their peaks.
+	Labels []Label
+
+	// Styles overrides the style of layers by name (LayerTerrain, LayerGrid,
+	// LayerInfluence, LayerPOIs, LayerLabels); layers not listed use their
+	// default style.
+	Styles map[string]LayerStyle
+}
+
+// Names of the layers Render composites, bottom to top.
+const (
+	LayerTerrain   = "terrain"
+	LayerGrid      = "grid"
+	LayerInfluence = "influence"
+	LayerPOIs      = "pois"
+	LayerLabels    = "labels"
+)
+
+// defaultStyles are used for layers missing from Options.Styles.
+var defaultStyles = map[string]LayerStyle{
+	LayerTerrain:   DefaultStyle,
+	LayerGrid:      {Visible: false, Opacity: 0.35, Mode: BlendMultiply},
+	LayerInfluence: {Visible: false, Opacity: 0.6, Mode: BlendNormal},
+	LayerPOIs:      DefaultStyle,
+	LayerLabels:    {Visible: false, Opacity: 1, Mode: BlendNormal},
+}
+
+// Style returns the style Render uses for the named layer.
+func (o Options) Style(name string) LayerStyle {
+	if s, ok := o.Styles[name]; ok {
+		return s
+	}
+	return defaultStyles[name]
+}
+
+var gridColor = color.RGBA{R: 20, G: 20, B: 20, A: 255}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// shade scales the RGB channels of c by (1 + f), clamped to the valid range.
+func shade(c color.RGBA, f float64) color.RGBA {
+	scale := func(v uint8) uint8 {
+		return uint8(clamp01(float64(v)/255*(1+f)) * 255)
+	}
+	return color.RGBA{R: scale(c.R), G: scale(c.G), B: scale(c.B), A: c.A}
+}
+
+// mix linearly blends from a to b by t in [0,1].
+func mix(a, b color.RGBA, t float64) color.RGBA {
+	t = clamp01(t)
+	lerp := func(u, v uint8) uint8 {
+		return uint8(float64(u) + (float64(v)-float64(u))*t)
+	}
+	return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: lerp(a.A, b.A)}
+}
+
+// waterHighlight returns the sun glint in [0,1] on wind-driven waves at (x, y).
+func waterHighlight(p *perlin.Perlin, x, y float64) float64 {
+	const waveFreq = 0.08
+	const waveHeight = 2.5
+	// slope of the wave field by central differences
+	hx := (p.Noise2DRaw(x+1, y, waveFreq) - p.Noise2DRaw(x-1, y, waveFreq)) * 0.5 * waveHeight
+	hy := (p.Noise2DRaw(x, y+1, waveFreq) - p.Noise2DRaw(x, y-1, waveFreq)) * 0.5 * waveHeight
+	return shading.Specular(-hx, -hy, 1, 400)
+}
+
+// detailNoise returns a signed, high-frequency texture value in approx [-1,1]
+// whose character depends on the terrain band: ripples on water, wind ripples
+// on sand, speckle on grass and grain on rock. Snow is left clean.
+func detailNoise(p *perlin.Perlin, band color.RGBA, x, y float64) float64 {
+	switch band {
+	case deepWaterColor, waterColor:
+		// long, soft swell lines
+		return 0.5 * math.Sin(x*0.15+y*0.05+p.Noise2DRaw(x, y, 0.05)*4)
+	case shoreColor:
+		// tight ripples bent by noise
+		return math.Sin((x+y*0.3)*0.8 + p.Noise2DRaw(x, y, 0.08)*3)
+	case landColor, highLandColor:
+		// fine speckle
+		return p.Noise2DRaw(x, y, 0.45)*0.7 + p.Noise2DRaw(x+50, y+50, 0.9)*0.5
+	case mountainColor:
+		// grain stretched across the slope
+		return p.Noise2DRaw(x*0.3, y*1.5, 0.35)
+	default:
+		return 0
+	}
+}
+
+// bandColor returns the base color of the elevation band noiseValue falls in.
+func bandColor(noiseValue, seaLevel float64) color.RGBA {
+	if noiseValue < seaLevel-0.15 {
+		return deepWaterColor
+	} else if noiseValue < seaLevel {
+		return waterColor
+	} else if noiseValue < seaLevel+0.04 {
+		return shoreColor
+	} else if noiseValue < seaLevel+0.10 {
+		return landColor
+	} else if noiseValue < seaLevel+0.20 {
+		return highLandColor
+	} else if noiseValue < seaLevel+0.30 {
+		return mountainColor
+	}
+	return highMountainColor
+}
+
+// FloodedPOIs returns the POIs of w below the flooded sea level of opts, and
+// the subset of those lost in the latest flood step.
+func FloodedPOIs(w *world.World, opts Options) (submerged, newlySubmerged []poi.Point) {
+	floodLevel := w.Params.SeaLevel + opts.FloodRise
+	submerged = poi.Submerged(w.POIs, w.Heights(), floodLevel)
+	for _, pnt := range submerged {
+		if w.ElevationAt(pnt.X, pnt.Y) >= floodLevel-opts.FloodStep {
+			newlySubmerged = append(newlySubmerged, pnt)
+		}
+	}
+	return submerged, newlySubmerged
+}
+
+// Render draws w as a colored map with its POIs, reporting the shading and
+// color stages on events (which may be nil). Terrain, grid, POI influence,
+// POIs and labels are drawn as separate layers and composited according to
+// opts.Styles.
+func Render(w *world.World, opts Options, events *world.Events) *image.RGBA {
+	width, height := w.Params.Width, w.Params.Height
+
+	var layers []Layer
+	for _, name := range []string{LayerTerrain, LayerGrid, LayerInfluence, LayerPOIs, LayerLabels} {
+		style := opts.Style(name)
+		if !style.Visible {
+			continue
+		}
+		var img *image.RGBA
+		switch name {
+		case LayerTerrain:
+			img = terrainLayer(w, opts, events)
+		case LayerGrid:
+			img = gridLayer(width, height, opts.GridSpacing)
+		case LayerInfluence:
+			img = influenceLayer(w, opts)
+		case LayerPOIs:
+			img = poiLayer(w, opts)
+		case LayerLabels:
+			img = labelsLayer(w, opts)
+		}
+		layers = append(layers, Layer{Name: name, Image: img, Style: style})
+	}
+
+	out := Composite(width, height, layers)
+	events.LayerReady(world.LayerImage, out)
+	return out
+}
+
+// terrainLayer draws the colored, shaded elevation with water effects and flooding.
+func terrainLayer(w *world.World, opts Options, events *world.Events) *image.RGBA {
+	width, height := w.Params.Width, w.Params.Height
+	seaLevel := w.Params.SeaLevel
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// texture noise shares the world seed
+	p := perlin.NewPerlin(w.Params.Seed)
+
+	// valley darkening needs the whole heightfield, so it runs before coloring
+	done := events.Track(world.StageShading)
+	var ao []float64
+	if opts.AOStrength > 0 {
+		ao = shading.AmbientOcclusion(w.Heights(), width, height, aoRadii, opts.AOStrength*aoGain)
+	}
+	var shoreDist []int
+	if opts.CoastalFoam {
+		shoreDist = shading.ShoreDistance(w.Heights(), width, height, seaLevel, foamWidth)
+	}
+	done()
+
+	done = events.Track(world.StageColor)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			noiseValue := w.ElevationAt(x, y)
+			band := bandColor(noiseValue, seaLevel)
+			pixelColor := band
+			if opts.HighContrast {
+				pixelColor = highContrastColors[band]
+			}
+
+			// per-band surface texture
+			if opts.DetailIntensity > 0 {
+				pixelColor = shade(pixelColor, detailNoise(p, band, float64(x), float64(y))*opts.DetailIntensity*0.25)
+			}
+
+			// ambient occlusion on land
+			if ao != nil && noiseValue >= seaLevel {
+				pixelColor = shade(pixelColor, -ao[y*width+x])
+			}
+
+			// water glint and surf
+			if noiseValue < seaLevel {
+				if opts.WaterGlint {
+					pixelColor = mix(pixelColor, glintColor, waterHighlight(p, float64(x), float64(y))*0.7)
+				}
+				if shoreDist != nil && shoreDist[y*width+x] <= foamWidth {
+					// foam thins out away from the coast and is broken up by noise
+					near := 1 - float64(shoreDist[y*width+x]-1)/float64(foamWidth)
+					broken := (p.Noise2DRaw(float64(x), float64(y), 0.2) + 1) * 0.5
+					if foam := near * broken; foam > 0.3 {
+						pixelColor = mix(pixelColor, foamColor, foam)
+					}
+				}
+			}
+
+			// land drowned by the flood, the latest step highlighted
+			if noiseValue >= seaLevel && noiseValue < seaLevel+opts.FloodRise {
+				if noiseValue >= seaLevel+opts.FloodRise-opts.FloodStep {
+					pixelColor = newlyFloodedColor
+				} else {
+					pixelColor = floodedColor
+				}
+			}
+			out.SetRGBA(x, y, pixelColor)
+		}
+	}
+	done()
+	return out
+}
+
+// gridLayer draws grid lines every spacing pixels on a transparent image.
+func gridLayer(width, height, spacing int) *image.RGBA {
+	if spacing <= 0 {
+		spacing = 64
+	}
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x%spacing == 0 || y%spacing == 0 {
+				out.SetRGBA(x, y, gridColor)
+			}
+		}
+	}
+	return out
+}
+
+// poiLayer draws the POI markers on a transparent image, dark once the flood has reached them.
+func poiLayer(w *world.World, opts Options) *image.RGBA {
+	width, height := w.Params.Width, w.Params.Height
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	submerged, _ := FloodedPOIs(w, opts)
+	isSubmerged := make(map[poi.Point]bool, len(submerged))
+	for _, pnt := range submerged {
+		isSubmerged[pnt] = true
+	}
+	for _, pnt := range w.POIs {
+		markerColor := poiColor
+		if opts.HighContrast {
+			markerColor = highContrastPoiColor
+		}
+		if isSubmerged[pnt] {
+			markerColor = submergedPoiColor
+		}
+		for i := -1; i <= 1; i++ {
+			for j := -1; j <= 1; j++ {
+				xx := pnt.X + i
+				yy := pnt.Y + j
+				if xx >= 0 && xx < width && yy >= 0 && yy < height {
+					out.SetRGBA(xx, yy, markerColor)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Preview renders the coarse world of pv and enlarges it to width x height,
+// to stand in for the full render while the world is still generating.
+func Preview(pv world.Preview, opts Options, width, height int) *image.RGBA {
+	// keep the grid lines where the full render draws them
+	spacing := opts.GridSpacing
+	if spacing <= 0 {
+		spacing = 64
+	}
+	opts.GridSpacing = max(spacing/pv.Step, 1)
+	// labels are placed on the full-size map and would be enlarged into smears
+	opts.Labels = nil
+	small := Render(pv.World, opts, nil)
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			out.SetRGBA(x, y, small.RGBAAt(x/pv.Step, y/pv.Step))
+		}
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package shading
+
+import (
+	"perlin_noise/heightfield"
+)
+
+// boxBlur returns the mean of field over a (2r+1)x(2r+1) window around every cell,
+// using a summed-area table so the cost does not depend on r. Windows are clipped
+// at the borders.
+func boxBlur(field []float64, width, height, r int) []float64 {
+	// sat has an extra leading row and column of zeros
+	sw := width + 1
+	sat := make([]float64, sw*(height+1))
+	for y := 0; y < height; y++ {
+		rowSum := 0.0
+		for x := 0; x < width; x++ {
+			rowSum += field[y*width+x]
+			sat[(y+1)*sw+x+1] = sat[y*sw+x+1] + rowSum
+		}
+	}
+
+	out := make([]float64, width*height)
+	for y := 0; y < height; y++ {
+		y0 := max(y-r, 0)
+		y1 := min(y+r+1, height)
+		for x := 0; x < width; x++ {
+			x0 := max(x-r, 0)
+			x1 := min(x+r+1, width)
+			sum := sat[y1*sw+x1] - sat[y0*sw+x1] - sat[y1*sw+x0] + sat[y0*sw+x0]
+			out[y*width+x] = sum / float64((x1-x0)*(y1-y0))
+		}
+	}
+	return out
+}
+
+// AmbientOcclusion estimates how enclosed each cell is by its surroundings, as the
+// amount by which blurred copies of the heightfield rise above it, averaged over
+// several blur radii. The result is row-major (index y*width+x) and is 0 on ridges
+// and open plains, growing towards 1 in valleys and canyons. strength scales the result.
+func AmbientOcclusion(noiseMap *heightfield.Field, width, height int, radii []int, strength float64) []float64 {
+	field := noiseMap.Data
+
+	ao := make([]float64, width*height)
+	if len(radii) == 0 {
+		return ao
+	}
+	for _, r := range radii {
+		blurred := boxBlur(field, width, height, r)
+		for i := range ao {
+			if d := blurred[i] - field[i]; d > 0 {
+				ao[i] += d
+			}
+		}
+	}
+
+	for i := range ao {
+		ao[i] = min(ao[i]/float64(len(radii))*strength, 1)
+	}
+	return ao
+}
@@ -0,0 +1,71 @@
+package shading
+
+import (
+	"math"
+
+	"perlin_noise/heightfield"
+)
+
+// LightDir is the unit direction towards the light used for map shading,
+// from the north-west and 45 degrees up as is usual for relief maps.
+// Map space: +X east, +Y south, +Z up.
+var LightDir = normalize(-1, -1, math.Sqrt2)
+
+func normalize(x, y, z float64) [3]float64 {
+	l := math.Sqrt(x*x + y*y + z*z)
+	return [3]float64{x / l, y / l, z / l}
+}
+
+// Specular returns a Blinn-Phong highlight in [0,1] for a surface normal (nx, ny, nz)
+// lit from LightDir and viewed from straight above.
+func Specular(nx, ny, nz, shininess float64) float64 {
+	n := normalize(nx, ny, nz)
+	// half vector between the light and the view direction (0,0,1)
+	h := normalize(LightDir[0], LightDir[1], LightDir[2]+1)
+	d := n[0]*h[0] + n[1]*h[1] + n[2]*h[2]
+	if d <= 0 {
+		return 0
+	}
+	return math.Pow(d, shininess)
+}
+
+// ShoreDistance returns, for every cell, the number of steps (8-connected) to the
+// nearest cell at or above seaLevel, in row-major order. Land cells are 0 and
+// cells further than maxDist from any land are maxDist+1.
+func ShoreDistance(noiseMap *heightfield.Field, width, height int, seaLevel float64, maxDist int) []int {
+	dist := make([]int, width*height)
+	var frontier []int
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			if noiseMap.At(x, y) >= seaLevel {
+				frontier = append(frontier, i)
+			} else {
+				dist[i] = maxDist + 1
+			}
+		}
+	}
+
+	// breadth-first flood from all land cells at once
+	for d := 1; d <= maxDist && len(frontier) > 0; d++ {
+		var next []int
+		for _, i := range frontier {
+			x, y := i%width, i/width
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					xx, yy := x+dx, y+dy
+					if xx < 0 || xx >= width || yy < 0 || yy >= height {
+						continue
+					}
+					j := yy*width + xx
+					if dist[j] > d {
+						dist[j] = d
+						next = append(next, j)
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+	return dist
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tapLayer is an invisible widget stacked over the map image that reports taps.
+type tapLayer struct {
+	widget.BaseWidget
+	onTapped func(fyne.Position)
+}
+
+func newTapLayer(onTapped func(fyne.Position)) *tapLayer {
+	t := &tapLayer{onTapped: onTapped}
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+func (t *tapLayer) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(canvas.NewRectangle(color.Transparent))
+}
+
+// Tapped implements fyne.Tappable.
+func (t *tapLayer) Tapped(e *fyne.PointEvent) {
+	if t.onTapped != nil {
+		t.onTapped(e.Position)
+	}
+}
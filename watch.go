@@ -0,0 +1,58 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// startWatch turns win into a screensaver: view fills the screen and next is
+// called on the GUI thread every interval to show a new world. Space freezes
+// and resumes the cycle, S calls save, and Escape restores the window.
+func startWatch(win fyne.Window, view fyne.CanvasObject, interval time.Duration, next, save func()) {
+	normal, title := win.Content(), win.Title()
+	keys := win.Canvas().OnTypedKey()
+
+	win.SetContent(view)
+	win.SetFullScreen(true)
+	win.SetTitle(title + " (watching)")
+
+	stop := make(chan struct{})
+	var frozen atomic.Bool
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if !frozen.Load() {
+					fyne.Do(next)
+				}
+			}
+		}
+	}()
+
+	win.Canvas().SetOnTypedKey(func(e *fyne.KeyEvent) {
+		switch e.Name {
+		case fyne.KeySpace:
+			if frozen.Load() {
+				frozen.Store(false)
+				win.SetTitle(title + " (watching)")
+			} else {
+				frozen.Store(true)
+				win.SetTitle(title + " (frozen)")
+			}
+		case fyne.KeyS:
+			save()
+		case fyne.KeyEscape:
+			close(stop)
+			win.Canvas().SetOnTypedKey(keys)
+			win.SetFullScreen(false)
+			win.SetTitle(title)
+			win.SetContent(normal)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package world
+
+import (
+	"context"
+	"sync"
+
+	"perlin_noise/modules"
+	"perlin_noise/perlin"
+)
+
+// continentKey holds every setting the continent mask depends on, so that
+// two runs with equal keys produce the same mask.
+type continentKey struct {
+	width, height int
+	seed          int64
+	noise         string
+	gradients     perlin.Gradients
+	style         string
+	hybridOffset  float64
+	hybridGain    float64
+	freq          float64
+	octaves       int
+	tilePeriod    int
+	planet        bool
+	decorrelate   bool
+}
+
+// newContinentKey returns the key of the mask that params produce with the
+// given number of continent octaves.
+func newContinentKey(params Params, octaves int) continentKey {
+	k := continentKey{
+		width:      params.Width,
+		height:     params.Height,
+		seed:       params.Seed,
+		noise:      params.ContinentNoise,
+		gradients:  params.Gradients,
+		style:      params.ContinentStyle,
+		freq:       params.ContinentFreq,
+		octaves:    octaves,
+		tilePeriod: params.TilePeriod,
+		planet:     params.Planet,
+		// tiling and planets replace the decorrelated noise, so it only
+		// matters without them
+		decorrelate: params.DecorrelateOctaves && params.TilePeriod == 0 && !params.Planet,
+	}
+	if k.noise == "" {
+		k.noise = params.Noise
+	}
+	// the hybrid settings only shape hybrid continents
+	if k.style == StyleHybrid {
+		k.hybridOffset, k.hybridGain = params.HybridOffset, params.HybridGain
+	}
+	return k
+}
+
+// terrainKey holds the params the sampled terrain depends on: all of them
+// but the sea level, height remapping, coast smoothing and POI spacing, which
+// are applied to the terrain afterwards.
+type terrainKey Params
+
+// newTerrainKey returns the key of the terrain that params produce.
+func newTerrainKey(params Params) terrainKey {
+	params.SeaLevel, params.MinDistance = 0, 0
+	params.HeightCurve, params.HeightExponent = "", 0
+	params.TerraceSteps, params.TerraceSharpness = 0, 0
+	params.CoastSmoothing = 0
+	return terrainKey(params)
+}
+
+// Names of the layers a Cache holds. Each is invalidated on its own, when
+// the key it was computed for changes.
+const (
+	// cacheContinents is the continent mask, keyed by continentKey.
+	cacheContinents = "continents"
+	// cacheTerrain is the sampled terrain before height remapping, keyed by
+	// terrainKey.
+	cacheTerrain = "terrain"
+)
+
+// grid is a read-only row-major layer, held in data or, for a run
+// WithQuantized, in q.
+type grid struct {
+	width int
+	data  []float64
+	q     *Quantized
+}
+
+// at returns the value of cell (x, y).
+func (g grid) at(x, y int) float64 {
+	if g.q != nil {
+		return g.q.At(x, y)
+	}
+	return g.data[y*g.width+x]
+}
+
+// cachedLayer is one intermediate result and the key it was computed for.
+type cachedLayer struct {
+	key any
+	grid
+}
+
+// Cache keeps intermediate layers of the last run by name, each with the key
+// of the settings it depends on. A run that only changes the sea level,
+// height remapping or POI spacing reuses the terrain and skips the noise
+// altogether; one that only changes the local detail, flow or falloff still
+// reuses the continent mask. The zero value is ready to use and a nil *Cache
+// disables caching. A Cache is safe for concurrent use.
+type Cache struct {
+	mu     sync.Mutex
+	layers map[string]cachedLayer
+}
+
+// layer returns the named width-wide layer for key, calling compute and
+// storing its result when the cached one was computed for a different key.
+// key must be comparable. With quantized the layer is kept, and returned,
+// only in quantized form, so a run gives the same result whether the layer
+// was cached or not.
+func (c *Cache) layer(name string, key any, width int, quantized bool, compute func() ([]float64, error)) (grid, error) {
+	if c != nil {
+		c.mu.Lock()
+		l, ok := c.layers[name]
+		c.mu.Unlock()
+		if ok && l.key == key && (l.q != nil) == quantized {
+			return l.grid, nil
+		}
+	}
+
+	// compute without the lock, as one layer may be built from another
+	data, err := compute()
+	if err != nil {
+		return grid{}, err
+	}
+	g := grid{width: width, data: data}
+	if quantized {
+		g = grid{width: width, q: quantize(data, width, len(data)/width)}
+	}
+	if c == nil {
+		return g, nil
+	}
+	c.mu.Lock()
+	if c.layers == nil {
+		c.layers = make(map[string]cachedLayer)
+	}
+	c.layers[name] = cachedLayer{key: key, grid: g}
+	c.mu.Unlock()
+	return g, nil
+}
+
+// continentMask returns the continent mask for key, sampling src at every
+// pixel when the cached one does not match. If ctx is done first, nothing is
+// cached and ctx's error is returned.
+func (c *Cache) continentMask(ctx context.Context, key continentKey, src modules.Module, events *Events, o options) (grid, error) {
+	return c.layer(cacheContinents, key, key.width, o.quantized, func() ([]float64, error) {
+		done := events.Track(StageContinents)
+		defer done()
+		mask := make([]float64, key.width*key.height)
+		prog := newProgress(events, StageContinents, len(mask))
+		err := parallelRows(ctx, key.height, o.workers, func(y int) {
+			modules.SampleRow(src, mask[y*key.width:(y+1)*key.width], 0, float64(y), 1)
+			prog.add(key.width)
+		})
+		return mask, err
+	})
+}
+
+// gridLookup is a module that reads a precomputed per-pixel grid. It must
+// only be sampled at whole pixel coordinates inside the map.
+type gridLookup struct {
+	grid grid
+}
+
+// Sample returns the grid value at pixel (x, y).
+func (g gridLookup) Sample(x, y float64) float64 {
+	return g.grid.at(int(x), int(y))
+}
@@ -0,0 +1,59 @@
+package world
+
+// coastNudge is how far past the sea level smoothCoast moves a cell that
+// changes sides, so it is drawn as shallow water or beach.
+const coastNudge = 0.002
+
+// smoothCoast runs passes of a cellular automaton over the land mask of
+// heights, a row-major width x height grid: land keeps only with at least 4
+// land cells among its 8 neighbors and water turns to land with at least 5.
+// Exposed headlands and one-cell islands are nibbled away and narrow inlets
+// and lakes fill in, while the relief away from the coast is untouched.
+// Neighbors off the map count as the cell itself, so the edges neither erode
+// nor grow.
+func smoothCoast(heights []float64, width, height int, seaLevel float64, passes int) {
+	land := make([]bool, len(heights))
+	for i, v := range heights {
+		land[i] = v >= seaLevel
+	}
+	next := make([]bool, len(land))
+
+	for pass := 0; pass < passes; pass++ {
+		changed := false
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				i := y*width + x
+				n := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if dx == 0 && dy == 0 {
+							continue
+						}
+						nx, ny := x+dx, y+dy
+						if nx < 0 || ny < 0 || nx >= width || ny >= height {
+							if land[i] {
+								n++
+							}
+						} else if land[ny*width+nx] {
+							n++
+						}
+					}
+				}
+				next[i] = land[i] && n >= 4 || !land[i] && n >= 5
+				changed = changed || next[i] != land[i]
+			}
+		}
+		land, next = next, land
+		if !changed {
+			break
+		}
+	}
+
+	for i, v := range heights {
+		if land[i] && v < seaLevel {
+			heights[i] = seaLevel + coastNudge
+		} else if !land[i] && v >= seaLevel {
+			heights[i] = seaLevel - coastNudge
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package world
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stage names one step of the generation pipeline.
+type Stage string
+
+const (
+	StageNoise      Stage = "noise"
+	StageContinents Stage = "continents"
+	StageShading    Stage = "shading"
+	StageColor      Stage = "color"
+	StagePOIs       Stage = "pois"
+)
+
+// Layer names a piece of generated output handed to OnLayerReady.
+type Layer string
+
+const (
+	LayerElevation Layer = "elevation"
+	LayerImage     Layer = "image"
+	LayerPOIs      Layer = "pois"
+	// LayerPreview carries a Preview after each coarse pass of the elevation.
+	LayerPreview Layer = "preview"
+)
+
+// Observer receives pipeline events. Any of the callbacks may be nil.
+// Callbacks run on the generating goroutine, so UI code must hop to its own
+// thread and no callback should block for long.
+type Observer struct {
+	OnStageStart    func(stage Stage)
+	OnStageComplete func(stage Stage, elapsed time.Duration)
+	// OnLayerReady is called with the finished layer; data must be treated as read-only.
+	OnLayerReady func(layer Layer, data any)
+	// OnProgress reports that done of the total samples of stage are
+	// finished. It is called at most once per percent, possibly from
+	// several goroutines at once.
+	OnProgress func(stage Stage, done, total int)
+}
+
+// Events dispatches pipeline events to its subscribed observers.
+// The zero value is ready to use and a nil *Events drops all events.
+type Events struct {
+	mu        sync.RWMutex
+	observers []Observer
+}
+
+// Subscribe adds o to the observers notified of future events.
+func (e *Events) Subscribe(o Observer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.observers = append(e.observers, o)
+}
+
+// With returns new Events that notify e's observers and then extra, for
+// observers that belong to a single run. Later subscriptions to e do not
+// reach it. A nil e gives Events notifying extra alone.
+func (e *Events) With(extra ...Observer) *Events {
+	var observers []Observer
+	if e != nil {
+		e.mu.RLock()
+		observers = append(observers, e.observers...)
+		e.mu.RUnlock()
+	}
+	return &Events{observers: append(observers, extra...)}
+}
+
+func (e *Events) each(fn func(o Observer)) {
+	if e == nil {
+		return
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, o := range e.observers {
+		fn(o)
+	}
+}
+
+// StageStart announces that stage has begun.
+func (e *Events) StageStart(stage Stage) {
+	e.each(func(o Observer) {
+		if o.OnStageStart != nil {
+			o.OnStageStart(stage)
+		}
+	})
+}
+
+// StageComplete announces that stage finished after elapsed.
+func (e *Events) StageComplete(stage Stage, elapsed time.Duration) {
+	e.each(func(o Observer) {
+		if o.OnStageComplete != nil {
+			o.OnStageComplete(stage, elapsed)
+		}
+	})
+}
+
+// LayerReady hands a finished layer to the observers.
+func (e *Events) LayerReady(layer Layer, data any) {
+	e.each(func(o Observer) {
+		if o.OnLayerReady != nil {
+			o.OnLayerReady(layer, data)
+		}
+	})
+}
+
+// Progress reports that done of the total samples of stage are finished.
+func (e *Events) Progress(stage Stage, done, total int) {
+	e.each(func(o Observer) {
+		if o.OnProgress != nil {
+			o.OnProgress(stage, done, total)
+		}
+	})
+}
+
+// progress counts the finished samples of a stage and reports them on
+// events whenever another percent is done. It is safe for concurrent use.
+type progress struct {
+	events *Events
+	stage  Stage
+	total  int64
+	done   atomic.Int64
+}
+
+func newProgress(events *Events, stage Stage, total int) *progress {
+	return &progress{events: events, stage: stage, total: int64(max(total, 1))}
+}
+
+// add records n more finished samples.
+func (p *progress) add(n int) {
+	done := p.done.Add(int64(n))
+	if (done-int64(n))*100/p.total != done*100/p.total {
+		p.events.Progress(p.stage, int(done), int(p.total))
+	}
+}
+
+// Track announces the start of stage and returns a func that announces its
+// completion with the elapsed time, for use as `defer events.Track(StageNoise)()`
+// or called directly at the end of the stage.
+func (e *Events) Track(stage Stage) func() {
+	start := time.Now()
+	e.StageStart(stage)
+	return func() {
+		e.StageComplete(stage, time.Since(start))
+	}
+}
+
+// LogObserver returns an observer that logs every completed stage with its duration.
+func LogObserver() Observer {
+	return Observer{
+		OnStageComplete: func(stage Stage, elapsed time.Duration) {
+			log.Printf("stage %s done in %v", stage, elapsed.Round(time.Millisecond))
+		},
+	}
+}
+
+// Timings records how long each stage of a run took, in completion order.
+// The zero value is ready to use.
+type Timings struct {
+	mu      sync.Mutex
+	stages  []Stage
+	elapsed map[Stage]time.Duration
+}
+
+// Observer returns an observer that records completed stages into t.
+func (t *Timings) Observer() Observer {
+	return Observer{
+		OnStageComplete: func(stage Stage, elapsed time.Duration) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.elapsed == nil {
+				t.elapsed = make(map[Stage]time.Duration)
+			}
+			if _, ok := t.elapsed[stage]; !ok {
+				t.stages = append(t.stages, stage)
+			}
+			t.elapsed[stage] += elapsed
+		},
+	}
+}
+
+// Reset forgets all recorded stages, ready for the next run.
+func (t *Timings) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stages = nil
+	t.elapsed = nil
+}
+
+// String lists the recorded stages with their durations, such as
+// "noise 120ms, color 35ms".
+func (t *Timings) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parts := make([]string, len(t.stages))
+	for i, stage := range t.stages {
+		parts[i] = fmt.Sprintf("%s %v", stage, t.elapsed[stage].Round(time.Millisecond))
+	}
+	return strings.Join(parts, ", ")
+}
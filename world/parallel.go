@@ -0,0 +1,94 @@
+package world
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// rowBand is how many rows a worker takes at a time: small enough to keep
+// every core busy to the end, large enough to keep the handoffs rare.
+const rowBand = 8
+
+// Option configures a Generate call.
+type Option func(*options)
+
+type options struct {
+	workers      int
+	quantized    bool
+	desirability func(w *World) []float64
+}
+
+// WithWorkers caps the goroutines generation runs on; n <= 0 means one per CPU.
+// Every pixel is computed on its own and written to its own slot, so the world
+// is bit-identical whatever n is, which is what headless batch runs need.
+func WithWorkers(n int) Option {
+	return func(o *options) { o.workers = n }
+}
+
+// WithQuantized keeps the world's elevation, and the layers a Cache holds,
+// as 16-bit Quantized values: a quarter of the memory of float64, for huge
+// worlds, at a precision of 1/65535 of each layer's range. The World's
+// Elevation field is then nil; ElevationAt and Heights read through the
+// quantized values. A run reads the same values from a Cache as it would
+// compute afresh, so caching does not change its output.
+func WithQuantized() Option {
+	return func(o *options) { o.quantized = true }
+}
+
+// WithPOIDesirability places the POIs by desirability: fn gets the world
+// with its elevation but no POIs yet, and returns a value in [0,1] for every
+// cell, row-major, such as its habitability. Candidate POIs are kept with
+// the desirability of their cell as probability (see poi.PoissonDiskWeighted).
+func WithPOIDesirability(fn func(w *World) []float64) Option {
+	return func(o *options) { o.desirability = fn }
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.workers <= 0 {
+		o.workers = runtime.NumCPU()
+	}
+	return o
+}
+
+// parallelRows calls fn for every row in [0, rows), spread over at most
+// workers goroutines in bands of rowBand rows. fn must be safe to call
+// concurrently for different rows. Once ctx is done no new band is started
+// and ctx's error is returned.
+func parallelRows(ctx context.Context, rows, workers int, fn func(row int)) error {
+	workers = min(workers, (rows+rowBand-1)/rowBand)
+	if workers <= 1 {
+		for r := 0; r < rows; r++ {
+			if r%rowBand == 0 && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fn(r)
+		}
+		return nil
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				start := int(next.Add(rowBand)) - rowBand
+				if start >= rows || ctx.Err() != nil {
+					return
+				}
+				for r := start; r < min(start+rowBand, rows); r++ {
+					fn(r)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
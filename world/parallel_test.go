@@ -0,0 +1,44 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestWorkersBitIdentical generates the same worlds on different worker
+// counts, which must all give the same elevation and POIs. Run it with
+// -race to check the workers share nothing. The height is not a multiple of
+// rowBand, so the last band is a short one.
+func TestWorkersBitIdentical(t *testing.T) {
+	cases := map[string]func(p *Params){
+		"default": func(p *Params) {},
+		"ridged curl flow": func(p *Params) {
+			p.TerrainStyle = StyleRidged
+			p.CurlFlow = true
+			p.Turbulence = 0.3
+		},
+		"tiled decorrelated": func(p *Params) {
+			p.TilePeriod = 4
+			p.DecorrelateOctaves = true
+			p.CoastSmoothing = 2
+		},
+		"planet": func(p *Params) { p.Planet = true },
+	}
+	for name, set := range cases {
+		params := DefaultParams(96, 100)
+		set(&params)
+		want, err := Generate(params, nil, WithWorkers(1))
+		if want == nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, workers := range []int{2, 3, 7, 16} {
+			got, _ := Generate(params, nil, WithWorkers(workers))
+			if !reflect.DeepEqual(got.Elevation, want.Elevation) {
+				t.Errorf("%s: elevation on %d workers differs from 1 worker", name, workers)
+			}
+			if !reflect.DeepEqual(got.POIs, want.POIs) {
+				t.Errorf("%s: POIs on %d workers differ from 1 worker", name, workers)
+			}
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package world
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"perlin_noise/modules"
+	"perlin_noise/perlin"
+)
+
+// Noise backends selectable with Params.Noise.
+const (
+	NoisePerlin        = "perlin"
+	NoiseSimplex       = "simplex"
+	NoiseOpenSimplex2F = "opensimplex2f"
+	NoiseOpenSimplex2S = "opensimplex2s"
+)
+
+// NoiseBackends lists the valid values of Params.Noise.
+var NoiseBackends = []string{NoisePerlin, NoiseSimplex, NoiseOpenSimplex2F, NoiseOpenSimplex2S}
+
+// Value noise backends, cheaper and blockier than gradient noise. They are only
+// offered for the continent mask, whose low frequency hides their artifacts.
+const (
+	NoiseValue = "value"
+	NoiseCubic = "cubic"
+)
+
+// ContinentNoiseBackends lists the valid non-empty values of Params.ContinentNoise.
+var ContinentNoiseBackends = append(slices.Clone(NoiseBackends), NoiseValue, NoiseCubic)
+
+// Terrain styles selectable with Params.TerrainStyle.
+const (
+	StyleFBM    = "fbm"
+	StyleRidged = "ridged"
+	StyleBillow = "billow"
+	StyleHybrid = "hybrid"
+)
+
+// TerrainStyles lists the valid values of Params.TerrainStyle and Params.ContinentStyle.
+var TerrainStyles = []string{StyleFBM, StyleRidged, StyleBillow, StyleHybrid}
+
+// Params holds every parameter that affects world generation.
+type Params struct {
+	Width, Height int
+
+	// Noise selects the gradient noise used for the terrain (one of NoiseBackends).
+	Noise string
+
+	// Gradients is the gradient set of the Perlin noise (one of
+	// perlin.GradientSets); more directions give fewer diamond artifacts.
+	Gradients perlin.Gradients
+
+	// TerrainStyle selects how the octaves of the local detail are combined
+	// (one of TerrainStyles): plain FBM, ridged multifractal, billow or
+	// hybrid multifractal.
+	TerrainStyle string
+	// HybridOffset and HybridGain shape the hybrid multifractal style: how far
+	// each octave is raised, and how quickly peaks gain detail.
+	HybridOffset float64
+	HybridGain   float64
+
+	Seed        int64
+	Scale       float64
+	Octaves     int
+	Persistence float64
+	Lacunarity  float64
+	// AdaptiveOctaves skips the octaves too faint to change the rendered
+	// map, which saves time at high octave counts.
+	AdaptiveOctaves bool
+	// DecorrelateOctaves rotates and offsets each octave of the terrain and
+	// continent noise so their grid artifacts do not line up. It has no
+	// effect with tiling or planet mode.
+	DecorrelateOctaves bool
+
+	// ContinentNoise selects the noise of the continent mask (one of
+	// ContinentNoiseBackends); empty means the same as Noise.
+	ContinentNoise   string
+	ContinentStyle   string // one of TerrainStyles
+	ContinentFreq    float64
+	ContinentOctaves int
+	ContinentWeight  float64
+
+	// Turbulence adds creased, absolute-value noise to the heights (0 = off).
+	Turbulence float64
+
+	Falloff       float64
+	FalloffWeight float64
+
+	SeaLevel    float64
+	MinDistance int64
+
+	FlowScale    float64
+	FlowStrength float64
+	// CurlFlow warps the terrain with divergence-free curl noise instead of
+	// two independent noise channels.
+	CurlFlow bool
+
+	// TilePeriod makes the terrain repeat every TilePeriod pixels in both
+	// directions (0 = off). The island falloff is skipped, since it would break
+	// the tiling; a map whose size is a multiple of the period tiles seamlessly.
+	// Only the Perlin backend supports it.
+	TilePeriod int
+
+	// Planet treats the map as an equirectangular projection of a whole
+	// planet: the terrain is sampled on a sphere, so the left and right edges
+	// meet without a seam and the poles are not pinched. The island falloff
+	// is skipped. Only the Perlin backend supports it.
+	Planet bool
+
+	// HeightCurve, HeightExponent and the terrace settings reshape the land
+	// above SeaLevel, in that order. HeightCurve is one of HeightCurves;
+	// HeightExponent above 1 flattens lowlands and sharpens peaks.
+	HeightCurve    string
+	HeightExponent float64
+	// TerraceSteps cuts the land into that many terraces (0 = off);
+	// TerraceSharpness in [0, 1] sets how flat they are.
+	TerraceSteps     int
+	TerraceSharpness float64
+
+	// CoastSmoothing runs that many passes of coastal erosion after the
+	// height remapping (0 = off): headlands are worn away and inlets filled,
+	// for a coast less ragged than the noise octaves make it.
+	CoastSmoothing int
+
+	// Recipe, when set, replaces the terrain built from the noise, continent,
+	// turbulence, falloff and flow settings above with a module graph loaded
+	// from a recipe. The height remapping still applies.
+	Recipe *modules.Node
+	// RecipeDir is the directory relative mask paths in Recipe are read
+	// from, normally the one holding the recipe file. Empty means the
+	// working directory.
+	RecipeDir string
+}
+
+// DefaultParams returns the default parameters (tweak to taste) for a width x height map.
+func DefaultParams(width, height int) Params {
+	return Params{
+		Width:  width,
+		Height: height,
+
+		Noise:        NoisePerlin,
+		Gradients:    perlin.Gradients4,
+		TerrainStyle: StyleFBM,
+		HybridOffset: 0.7,
+		HybridGain:   2.0,
+
+		Seed:        12345,
+		Scale:       0.006,
+		Octaves:     5,
+		Persistence: 0.5,
+		Lacunarity:  2.0,
+
+		ContinentStyle:   StyleFBM,
+		ContinentFreq:    0.004,
+		ContinentOctaves: 3,
+		ContinentWeight:  0.6,
+
+		Falloff:       1.8,
+		FalloffWeight: 0.6,
+
+		SeaLevel:    0.45,
+		MinDistance: 25,
+
+		FlowScale:    0.002,
+		FlowStrength: 15.0,
+
+		HeightCurve:      CurveLinear,
+		HeightExponent:   1.0,
+		TerraceSharpness: 0.8,
+	}
+}
+
+// Scaled returns p for a width x height map of the same terrain, such as a
+// quick low-resolution version of it: the noise frequencies, flow, POI
+// spacing, tile period and coast smoothing follow the change in size, which
+// is taken from the widths. Recipes give their frequencies in pixels of the
+// map, so they are not scaled.
+func (p Params) Scaled(width, height int) Params {
+	k := float64(width) / float64(p.Width)
+	p.Width, p.Height = width, height
+	p.Scale /= k
+	p.ContinentFreq /= k
+	p.FlowScale /= k
+	p.FlowStrength *= k
+	p.MinDistance = max(int64(math.Round(float64(p.MinDistance)*k)), 1)
+	if p.TilePeriod > 0 {
+		p.TilePeriod = max(int(math.Round(float64(p.TilePeriod)*k)), 1)
+	}
+	p.CoastSmoothing = int(math.Round(float64(p.CoastSmoothing) * k))
+	return p
+}
+
+// finite reports whether v is neither NaN nor infinite.
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Validate checks that each parameter is in range and that the parameters make
+// sense together. It returns nil for valid params, otherwise an error listing
+// every problem found.
+func (p Params) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(p.Width > 0 && p.Height > 0, "map size must be positive, got %dx%d", p.Width, p.Height)
+
+	check(slices.Contains(NoiseBackends, p.Noise), "noise must be one of %v, got %q", NoiseBackends, p.Noise)
+
+	check(slices.Contains(perlin.GradientSets, p.Gradients), "gradients must be one of %v, got %d", perlin.GradientSets, p.Gradients)
+
+	check(slices.Contains(TerrainStyles, p.TerrainStyle), "terrain style must be one of %v, got %q", TerrainStyles, p.TerrainStyle)
+
+	check(finite(p.HybridOffset) && p.HybridOffset >= 0, "hybrid offset must not be negative, got %g", p.HybridOffset)
+	check(finite(p.HybridGain) && p.HybridGain > 0, "hybrid gain must be greater than 0, got %g", p.HybridGain)
+
+	check(finite(p.Scale) && p.Scale > 0, "scale must be greater than 0, got %g", p.Scale)
+	check(p.Octaves >= 1, "octaves must be at least 1, got %d", p.Octaves)
+	check(finite(p.Persistence) && p.Persistence > 0 && p.Persistence <= 1, "persistence must be in (0, 1], got %g", p.Persistence)
+	check(finite(p.Lacunarity) && p.Lacunarity > 1, "lacunarity must be greater than 1, got %g", p.Lacunarity)
+
+	check(p.ContinentNoise == "" || slices.Contains(ContinentNoiseBackends, p.ContinentNoise), "continent noise must be empty or one of %v, got %q", ContinentNoiseBackends, p.ContinentNoise)
+	check(slices.Contains(TerrainStyles, p.ContinentStyle), "continent style must be one of %v, got %q", TerrainStyles, p.ContinentStyle)
+	check(finite(p.ContinentFreq) && p.ContinentFreq > 0, "continent frequency must be greater than 0, got %g", p.ContinentFreq)
+	check(p.ContinentOctaves >= 1, "continent octaves must be at least 1, got %d", p.ContinentOctaves)
+	check(p.ContinentWeight >= 0 && p.ContinentWeight <= 1, "continent weight must be in [0, 1], got %g", p.ContinentWeight)
+
+	check(p.Turbulence >= 0 && p.Turbulence <= 1, "turbulence must be in [0, 1], got %g", p.Turbulence)
+
+	check(finite(p.Falloff) && p.Falloff > 0, "falloff must be greater than 0, got %g", p.Falloff)
+	check(p.FalloffWeight >= 0 && p.FalloffWeight <= 1, "falloff weight must be in [0, 1], got %g", p.FalloffWeight)
+
+	check(p.SeaLevel >= 0 && p.SeaLevel <= 1, "sea level must be in [0, 1], got %g", p.SeaLevel)
+	check(p.MinDistance >= 1, "min. distance must be at least 1, got %d", p.MinDistance)
+	if p.Width > 0 && p.Height > 0 {
+		check(p.MinDistance < int64(min(p.Width, p.Height)), "min. distance (%d) must be smaller than the map (%dx%d)", p.MinDistance, p.Width, p.Height)
+	}
+
+	check(finite(p.FlowScale) && p.FlowScale >= 0, "flow scale must not be negative, got %g", p.FlowScale)
+	check(finite(p.FlowStrength) && p.FlowStrength >= 0, "flow strength must not be negative, got %g", p.FlowStrength)
+
+	check(p.TilePeriod >= 0, "tile period must not be negative, got %d", p.TilePeriod)
+	if p.TilePeriod > 0 {
+		check(p.Noise == NoisePerlin && (p.ContinentNoise == "" || p.ContinentNoise == NoisePerlin),
+			"tiling needs the %q noise for terrain and continents", NoisePerlin)
+		check(!p.CurlFlow, "curl flow cannot be combined with tiling")
+	}
+
+	if p.Planet {
+		check(p.Noise == NoisePerlin && (p.ContinentNoise == "" || p.ContinentNoise == NoisePerlin),
+			"planet mode needs the %q noise for terrain and continents", NoisePerlin)
+		check(!p.CurlFlow, "curl flow cannot be combined with planet mode")
+		check(p.TilePeriod == 0, "tiling cannot be combined with planet mode")
+	}
+
+	if p.Recipe != nil {
+		check(p.TilePeriod == 0, "tiling cannot be combined with a terrain recipe")
+		check(!p.Planet, "planet mode cannot be combined with a terrain recipe")
+	}
+
+	check(p.HeightCurve == "" || slices.Contains(HeightCurves, p.HeightCurve), "height curve must be empty or one of %v, got %q", HeightCurves, p.HeightCurve)
+	check(finite(p.HeightExponent) && p.HeightExponent > 0, "height exponent must be greater than 0, got %g", p.HeightExponent)
+	check(p.TerraceSteps >= 0, "terrace steps must not be negative, got %d", p.TerraceSteps)
+	check(p.TerraceSharpness >= 0 && p.TerraceSharpness <= 1, "terrace sharpness must be in [0, 1], got %g", p.TerraceSharpness)
+	check(p.CoastSmoothing >= 0, "coast smoothing must not be negative, got %d", p.CoastSmoothing)
+
+	return errors.Join(errs...)
+}
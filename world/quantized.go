@@ -0,0 +1,68 @@
+package world
+
+import (
+	"math"
+	"slices"
+
+	"perlin_noise/heightfield"
+)
+
+// Quantized is a compact copy of a world's elevation: one uint16 per cell,
+// scaled between the lowest and highest cell. It takes a quarter of the memory
+// of the float64 Elevation field, at a precision of 1/65535 of the height
+// range, well below anything a render can show.
+type Quantized struct {
+	Width, Height int
+	// a stored value q stands for Offset + q*Scale
+	Scale, Offset float64
+	Data          []uint16
+}
+
+// Quantize returns the elevation of w in quantized form. A world generated
+// WithQuantized returns the values it holds.
+func (w *World) Quantize() *Quantized {
+	if w.quantized != nil {
+		return w.quantized
+	}
+	return quantize(w.Elevation.Data, w.Params.Width, w.Params.Height)
+}
+
+// quantize packs data, a row-major width x height grid, scaled between its
+// lowest and highest value.
+func quantize(data []float64, width, height int) *Quantized {
+	q := &Quantized{Width: width, Height: height, Data: make([]uint16, len(data))}
+	if len(data) == 0 {
+		return q
+	}
+	lo, hi := slices.Min(data), slices.Max(data)
+	q.Offset = lo
+	if hi == lo {
+		// a flat grid stores only its offset
+		return q
+	}
+	q.Scale = (hi - lo) / math.MaxUint16
+	for i, v := range data {
+		q.Data[i] = uint16(math.Round((v - lo) / q.Scale))
+	}
+	return q
+}
+
+// At returns the height of cell (x, y), like World.ElevationAt.
+func (q *Quantized) At(x, y int) float64 {
+	return q.Offset + float64(q.Data[y*q.Width+x])*q.Scale
+}
+
+// Elevation expands q back into the float64 form of World.Elevation.
+func (q *Quantized) Elevation() *heightfield.Field {
+	f := heightfield.New(q.Width, q.Height)
+	q.expand(f.Data)
+	return f
+}
+
+// expand writes the heights q stands for into dst, which holds a value for
+// every cell.
+func (q *Quantized) expand(dst []float64) {
+	for i, v := range q.Data {
+		dst[i] = q.Offset + float64(v)*q.Scale
+	}
+}
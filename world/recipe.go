@@ -0,0 +1,123 @@
+package world
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg" // masks may be JPEG or PNG
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"perlin_noise/modules"
+	"perlin_noise/perlin"
+)
+
+// Flow fields and the extra octave style available to recipes.
+const (
+	FlowNoise       = "noise"
+	FlowCurl        = "curl"
+	StyleTurbulence = "turbulence"
+)
+
+// recipeEnv resolves the names used in recipes for a map made with params.
+func recipeEnv(params Params) modules.Env {
+	return modules.Env{
+		Width:  params.Width,
+		Height: params.Height,
+		Noise: func(name string, seed int64) (perlin.NoiseFunc, error) {
+			if !slices.Contains(ContinentNoiseBackends, name) {
+				return nil, fmt.Errorf("noise must be one of %v, got %q", ContinentNoiseBackends, name)
+			}
+			seed += params.Seed
+			noise := noiseFunc(name, perlin.NewPerlin(seed, perlin.WithGradients(params.Gradients)), seed)
+			if params.DecorrelateOctaves {
+				noise = perlin.Decorrelated(noise, seed)
+			}
+			return noise, nil
+		},
+		Style: func(name string) (perlin.FractalFunc, error) {
+			if name == StyleTurbulence {
+				return perlin.Turbulence, nil
+			}
+			if !slices.Contains(TerrainStyles, name) {
+				return nil, fmt.Errorf("style must be %q or one of %v, got %q", StyleTurbulence, TerrainStyles, name)
+			}
+			return fractalFunc(name, params), nil
+		},
+		Flow: func(name string, seed int64, freq float64) (func(x, y float64) (float64, float64), error) {
+			p := perlin.NewPerlin(params.Seed+seed, perlin.WithGradients(params.Gradients))
+			flow := p.NoiseFlow
+			switch name {
+			case FlowNoise:
+			case FlowCurl:
+				flow = p.CurlFlow
+			default:
+				return nil, fmt.Errorf("flow must be %q or %q, got %q", FlowNoise, FlowCurl, name)
+			}
+			return func(x, y float64) (float64, float64) {
+				return flow(x, y, freq)
+			}, nil
+		},
+		Image: func(path string) (image.Image, error) {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(params.RecipeDir, path)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			img, _, err := image.Decode(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			return img, nil
+		},
+	}
+}
+
+// Recipe returns the terrain that params build as a recipe, a starting point
+// for custom ones. Tiling and planet mode are not part of it.
+func Recipe(params Params) *modules.Node {
+	flow := FlowNoise
+	if params.CurlFlow {
+		flow = FlowCurl
+	}
+	fractal := func(style string, freq float64, octaves int, persistence, lacunarity float64) *modules.Node {
+		return &modules.Node{Type: "fractal", Noise: params.Noise, Style: style, Freq: freq, Octaves: octaves, Persistence: persistence, Lacunarity: lacunarity}
+	}
+	displace := func(src *modules.Node) *modules.Node {
+		return &modules.Node{Type: "displace", Flow: flow, Freq: params.FlowScale, Strength: params.FlowStrength, Sources: []*modules.Node{src}}
+	}
+
+	continent := fractal(params.ContinentStyle, params.ContinentFreq, params.ContinentOctaves, 0.5, 2.0)
+	if params.ContinentNoise != "" {
+		continent.Noise = params.ContinentNoise
+	}
+	sum := []*modules.Node{{
+		Type:  "scalebias",
+		Scale: 0.5,
+		Bias:  0.5,
+		Sources: []*modules.Node{{
+			Type:    "blend",
+			Sources: []*modules.Node{displace(fractal(params.TerrainStyle, params.Scale, params.Octaves, params.Persistence, params.Lacunarity)), continent},
+			Control: &modules.Node{Type: "const", Value: params.ContinentWeight},
+		}},
+	}}
+	if params.Turbulence > 0 {
+		sum = append(sum, &modules.Node{
+			Type:    "scalebias",
+			Scale:   params.Turbulence,
+			Bias:    -turbulenceMean * params.Turbulence,
+			Sources: []*modules.Node{displace(fractal(StyleTurbulence, params.Scale*turbulenceFreq, params.Octaves, params.Persistence, params.Lacunarity))},
+		})
+	}
+	sum = append(sum, &modules.Node{
+		Type:    "scalebias",
+		Scale:   -params.FalloffWeight,
+		Sources: []*modules.Node{{Type: "radial", Exponent: params.Falloff}},
+	})
+
+	return &modules.Node{Type: "clamp", Min: 0, Max: 1, Sources: []*modules.Node{{Type: "add", Sources: sum}}}
+}
@@ -0,0 +1,110 @@
+package world
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"perlin_noise/modules"
+)
+
+// TestRecipeMaskPath builds a recipe whose mask sits next to it in a temp
+// dir, away from the working directory: the relative path must be read from
+// RecipeDir, and the mask must shape the terrain.
+func TestRecipeMaskPath(t *testing.T) {
+	dir := t.TempDir()
+	// white on the left half, black on the right
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	f, err := os.Create(filepath.Join(dir, "mask.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	params := DefaultParams(32, 32)
+	params.Recipe = &modules.Node{Type: "mask", Path: "mask.png"}
+	if w, _ := Generate(params, nil); w != nil {
+		t.Fatal("mask found in the working directory instead of RecipeDir")
+	}
+
+	params.RecipeDir = dir
+	w, err := Generate(params, nil)
+	if w == nil {
+		t.Fatal(err)
+	}
+	if left, right := w.ElevationAt(2, 16), w.ElevationAt(29, 16); left != 1 || right != 0 {
+		t.Errorf("mask reads %v on the left and %v on the right, want 1 and 0", left, right)
+	}
+}
+
+// TestRecipeRoundTrip writes the recipe of the default params and reads it
+// back unchanged, and checks that it builds the same terrain as the params.
+func TestRecipeRoundTrip(t *testing.T) {
+	params := DefaultParams(64, 64)
+	recipe := Recipe(params)
+	var buf bytes.Buffer
+	if err := modules.WriteRecipe(&buf, recipe); err != nil {
+		t.Fatal(err)
+	}
+	read, err := modules.ReadRecipe(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, recipe) {
+		t.Fatalf("read back\n%+v\nwant\n%+v", read, recipe)
+	}
+	params.Recipe = read
+	if w, err := Generate(params, nil); w == nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRecipeFractalLimits checks that a fractal with too many octaves, or a
+// frequency or lacunarity that is not a positive number, is rejected rather
+// than generated.
+func TestRecipeFractalLimits(t *testing.T) {
+	valid := modules.Node{Type: "fractal", Noise: NoisePerlin, Style: StyleFBM, Freq: 0.01, Octaves: 4, Persistence: 0.5, Lacunarity: 2}
+	tests := []struct {
+		name string
+		set  func(n *modules.Node)
+	}{
+		{"zero octaves", func(n *modules.Node) { n.Octaves = 0 }},
+		{"a billion octaves", func(n *modules.Node) { n.Octaves = 1e9 }},
+		{"zero freq", func(n *modules.Node) { n.Freq = 0 }},
+		{"negative freq", func(n *modules.Node) { n.Freq = -0.01 }},
+		{"infinite freq", func(n *modules.Node) { n.Freq = math.Inf(1) }},
+		{"NaN lacunarity", func(n *modules.Node) { n.Lacunarity = math.NaN() }},
+		{"zero lacunarity", func(n *modules.Node) { n.Lacunarity = 0 }},
+		{"NaN persistence", func(n *modules.Node) { n.Persistence = math.NaN() }},
+	}
+	params := DefaultParams(64, 64)
+	for _, tt := range tests {
+		n := valid
+		tt.set(&n)
+		params.Recipe = &n
+		if w, err := Generate(params, nil); w != nil || !strings.Contains(fmt.Sprint(err), "fractal") {
+			t.Errorf("%s: got error %v, want a fractal error", tt.name, err)
+		}
+	}
+	n := valid
+	params.Recipe = &n
+	if w, err := Generate(params, nil); w == nil {
+		t.Errorf("valid fractal: %v", err)
+	}
+}
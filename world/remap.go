@@ -0,0 +1,52 @@
+package world
+
+import "perlin_noise/remap"
+
+// Height curves selectable with Params.HeightCurve.
+const (
+	CurveLinear        = "linear"
+	CurveFlattenPlains = "flatten plains"
+	CurvePlateaus      = "plateaus"
+	CurveHighlands     = "highlands"
+)
+
+// HeightCurves lists the valid values of Params.HeightCurve.
+var HeightCurves = []string{CurveLinear, CurveFlattenPlains, CurvePlateaus, CurveHighlands}
+
+// curvePoints holds the control points of each height curve, from the coast
+// (0) to the highest peak (1).
+var curvePoints = map[string][][2]float64{
+	CurveLinear:        {{0, 0}, {1, 1}},
+	CurveFlattenPlains: {{0, 0}, {0.4, 0.12}, {0.75, 0.45}, {1, 1}},
+	CurvePlateaus:      {{0, 0}, {0.25, 0.45}, {0.65, 0.55}, {1, 1}},
+	CurveHighlands:     {{0, 0}, {0.2, 0.45}, {1, 1}},
+}
+
+// heightRemap returns the function that reshapes land heights according to
+// params, or nil when every remapping is off. Only heights above the sea level
+// are touched, so the coastline stays where the noise put it.
+func heightRemap(params Params) func(float64) float64 {
+	curved := params.HeightCurve != "" && params.HeightCurve != CurveLinear
+	if !curved && params.HeightExponent == 1 && params.TerraceSteps == 0 {
+		return nil
+	}
+	var curve *remap.Curve
+	if curved {
+		// the presets are fixed and valid
+		curve, _ = remap.NewCurve(curvePoints[params.HeightCurve]...)
+	}
+
+	sea := params.SeaLevel
+	return func(v float64) float64 {
+		if v <= sea || sea >= 1 {
+			return v
+		}
+		t := (v - sea) / (1 - sea)
+		if curve != nil {
+			t = curve.At(t)
+		}
+		t = remap.Power(t, params.HeightExponent)
+		t = remap.Terrace(t, params.TerraceSteps, params.TerraceSharpness)
+		return sea + t*(1-sea)
+	}
+}
@@ -0,0 +1,393 @@
+package world
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"perlin_noise/heightfield"
+	"perlin_noise/modules"
+	"perlin_noise/noise/opensimplex"
+	"perlin_noise/perlin"
+	"perlin_noise/poi"
+)
+
+// World is the single output of generation: every layer that renderers and
+// exporters work from, together with the parameters that produced it.
+type World struct {
+	Params Params
+
+	// Elevation holds the normalized height in [0,1] of every cell. It is nil
+	// for a world generated WithQuantized; ElevationAt and Heights work
+	// either way.
+	Elevation *heightfield.Field
+	// quantized holds the elevation of a world generated WithQuantized.
+	quantized *Quantized
+
+	// POIs are the points of interest placed on land.
+	POIs []poi.Point
+}
+
+// turbulenceFreq is the frequency of the turbulence modifier relative to Params.Scale.
+const turbulenceFreq = 2.0
+
+// turbulenceMean is roughly the mean of perlin.Turbulence across the noise
+// backends; subtracting it keeps the modifier from raising the terrain overall.
+const turbulenceMean = 0.3
+
+// octaveThreshold is the share of the total amplitude below which adaptive
+// octave truncation drops an octave: half a step of an 8-bit color channel.
+const octaveThreshold = 1.0 / 512
+
+// passSteps are the strides of the interleaved elevation passes, coarsest
+// first; every pass but the last is published as a Preview.
+var passSteps = []int{8, 4, 1}
+
+// Preview is a coarse early look at a world being generated.
+type Preview struct {
+	// World holds the elevation sampled every Step pixels, so its size is
+	// the full size divided by Step. It has no POIs.
+	World *World
+	Step  int
+}
+
+// newPreview copies every step-th sample of heights, a row-major grid of the
+// full map size, into a Preview, reshaping them with reshape unless it is nil.
+func newPreview(params Params, heights []float64, step int, reshape func(float64) float64) Preview {
+	full := params.Width
+	params.Width = (params.Width + step - 1) / step
+	params.Height = (params.Height + step - 1) / step
+
+	small := &World{Params: params, Elevation: heightfield.New(params.Width, params.Height)}
+	for y := 0; y < params.Height; y++ {
+		for x := 0; x < params.Width; x++ {
+			v := heights[y*step*full+x*step]
+			if reshape != nil {
+				v = reshape(v)
+			}
+			small.Elevation.Set(x, y, v)
+		}
+	}
+	return Preview{World: small, Step: step}
+}
+
+// noiseFunc returns the signed noise basis of the named backend, seeded like p.
+func noiseFunc(noise string, p *perlin.Perlin, seed int64) perlin.NoiseFunc {
+	switch noise {
+	case NoiseSimplex:
+		return p.Simplex2DRaw
+	case NoiseOpenSimplex2F:
+		return opensimplex.New(seed, opensimplex.F).Noise2DRaw
+	case NoiseOpenSimplex2S:
+		return opensimplex.New(seed, opensimplex.S).Noise2DRaw
+	case NoiseValue:
+		return p.Value2DRaw
+	case NoiseCubic:
+		return p.Cubic2DRaw
+	default:
+		return p.Noise2DRaw
+	}
+}
+
+// fractalFunc returns the octave combiner of the named terrain style, taking
+// any extra settings from params.
+func fractalFunc(style string, params Params) perlin.FractalFunc {
+	switch style {
+	case StyleHybrid:
+		return func(noise perlin.NoiseFunc, x, y, baseFreq float64, octaves int, persistence, lacunarity float64) float64 {
+			return perlin.Hybrid(noise, x, y, baseFreq, octaves, persistence, lacunarity, params.HybridOffset, params.HybridGain)
+		}
+	case StyleRidged:
+		return perlin.Ridged
+	case StyleBillow:
+		return perlin.Billow
+	default:
+		return perlin.FBM
+	}
+}
+
+// continentGraph returns the large-scale continent mask of params. row, if
+// not nil, fills a row of continentNoise at once; FBM continents use it.
+func continentGraph(params Params, continentNoise perlin.NoiseFunc, row perlin.RowFunc, octaves int) modules.Module {
+	f := modules.Fractal{
+		Noise:       continentNoise,
+		Freq:        params.ContinentFreq,
+		Octaves:     octaves,
+		Persistence: 0.5,
+		Lacunarity:  2.0,
+	}
+	if params.ContinentStyle == StyleFBM {
+		f.Row = row
+	} else {
+		f.Style = fractalFunc(params.ContinentStyle, params)
+	}
+	return f
+}
+
+// terrainGraph wires the terrain of params into a module graph: flow-warped
+// local detail blended with the continent mask, optional turbulence and the
+// island falloff, clamped to [0,1].
+func terrainGraph(params Params, noise perlin.NoiseFunc, continent modules.Module, flow func(x, y, freq float64) (float64, float64), octaves int) modules.Module {
+	// signed flow in [-1,1]
+	warp := func(x, y float64) (float64, float64) {
+		return flow(x, y, params.FlowScale)
+	}
+
+	// local detail
+	local := modules.Displace{
+		Source: modules.Fractal{
+			Noise:       noise,
+			Style:       fractalFunc(params.TerrainStyle, params),
+			Freq:        params.Scale,
+			Octaves:     octaves,
+			Persistence: params.Persistence,
+			Lacunarity:  params.Lacunarity,
+		},
+		Flow:     warp,
+		Strength: params.FlowStrength,
+	}
+	combined := modules.Add{Sources: []modules.Module{
+		modules.ScaleBias{
+			Source: modules.Blend{A: local, B: continent, Control: modules.Const{Value: params.ContinentWeight}},
+			Scale:  0.5,
+			Bias:   0.5,
+		},
+	}}
+
+	// creased turbulence roughens the terrain into badlands
+	if params.Turbulence > 0 {
+		combined.Sources = append(combined.Sources, modules.ScaleBias{
+			Source: modules.Displace{
+				Source: modules.Fractal{
+					Noise:       noise,
+					Style:       perlin.Turbulence,
+					Freq:        params.Scale * turbulenceFreq,
+					Octaves:     octaves,
+					Persistence: params.Persistence,
+					Lacunarity:  params.Lacunarity,
+				},
+				Flow:     warp,
+				Strength: params.FlowStrength,
+			},
+			Scale: params.Turbulence,
+			Bias:  -turbulenceMean * params.Turbulence,
+		})
+	}
+
+	// tiling maps and planets have no edges to fall off at
+	if params.TilePeriod == 0 && !params.Planet {
+		centerX := float64(params.Width) / 2.0
+		centerY := float64(params.Height) / 2.0
+		combined.Sources = append(combined.Sources, modules.ScaleBias{
+			Source: modules.Radial{CenterX: centerX, CenterY: centerY, Radius: math.Hypot(centerX, centerY), Exponent: params.Falloff},
+			Scale:  -params.FalloffWeight,
+		})
+	}
+
+	return modules.Clamp{Source: combined, Min: 0, Max: 1}
+}
+
+// Generate builds a world from params, reporting its stages and layers on events
+// (which may be nil). Invalid params are rejected with the error from Validate.
+// If no POIs can be placed, Generate still returns the world, along with the
+// error from poi.PoissonDisk.
+func Generate(params Params, events *Events, opts ...Option) (*World, error) {
+	return GenerateCached(context.Background(), params, events, nil, opts...)
+}
+
+// GenerateCached is Generate, reusing the terrain and continent mask held in
+// cache (which may be nil) when params have not changed them, and storing the
+// new ones otherwise. Once ctx is done generation stops at the next band of
+// rows and returns a nil world with ctx's error.
+func GenerateCached(ctx context.Context, params Params, events *Events, cache *Cache, opts ...Option) (*World, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	o := newOptions(opts)
+
+	width, height := params.Width, params.Height
+	w := &World{Params: params}
+	reshape := heightRemap(params)
+
+	// the sea level and height remapping only reshape the sampled terrain, so
+	// changing them skips the noise entirely
+	raw, err := cache.layer(cacheTerrain, newTerrainKey(params), width, o.quantized, func() ([]float64, error) {
+		return sampleTerrain(ctx, params, events, cache, o, reshape)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the cached terrain is shared, so the world gets its own copy
+	heights := make([]float64, width*height)
+	err = parallelRows(ctx, height, o.workers, func(y int) {
+		for x := 0; x < width; x++ {
+			v := raw.at(x, y)
+			if reshape != nil {
+				v = reshape(v)
+			}
+			heights[y*width+x] = v
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if params.CoastSmoothing > 0 {
+		smoothCoast(heights, width, height, params.SeaLevel, params.CoastSmoothing)
+	}
+	elevation := &heightfield.Field{Width: width, Height: height, Data: heights}
+	if o.quantized {
+		// POIs are placed on the heights the world keeps, so they are
+		// rounded in place rather than expanded into another field
+		w.quantized = quantize(heights, width, height)
+		w.quantized.expand(heights)
+	} else {
+		w.Elevation = elevation
+	}
+	events.LayerReady(LayerElevation, elevation)
+
+	done := events.Track(StagePOIs)
+	var desirability []float64
+	if o.desirability != nil {
+		desirability = o.desirability(w)
+	}
+	// Each POI run needs its own source to be threadsafe
+	poiRand := rand.New(rand.NewSource(params.Seed))
+	pois, _, err := poi.PoissonDiskWeighted(params.MinDistance, int64(width), int64(height), poiRand, elevation, params.SeaLevel, desirability)
+	w.POIs = pois
+	done()
+	events.LayerReady(LayerPOIs, w.POIs)
+
+	return w, err
+}
+
+// sampleTerrain samples the terrain of params at every pixel, before the
+// height remapping, in coarse to fine passes. Each pass but the last is
+// published on events as a preview, remapped with reshape.
+func sampleTerrain(ctx context.Context, params Params, events *Events, cache *Cache, o options, reshape func(float64) float64) ([]float64, error) {
+	width, height := params.Width, params.Height
+
+	// local perlin instance
+	p := perlin.NewPerlin(params.Seed, perlin.WithGradients(params.Gradients))
+	flow := p.NoiseFlow
+	if params.CurlFlow {
+		flow = p.CurlFlow
+	}
+	noise := noiseFunc(params.Noise, p, params.Seed)
+	continentNoise := noise
+	if params.ContinentNoise != "" {
+		continentNoise = noiseFunc(params.ContinentNoise, p, params.Seed)
+	}
+	// plain Perlin continents are sampled a row at a time
+	var continentRow perlin.RowFunc
+	if params.ContinentNoise == NoisePerlin || params.ContinentNoise == "" && params.Noise == NoisePerlin {
+		continentRow = p.FillRow
+	}
+	if params.DecorrelateOctaves {
+		continentRow = nil
+		noise = perlin.Decorrelated(noise, params.Seed)
+		continentNoise = perlin.Decorrelated(continentNoise, params.Seed)
+	}
+	if params.TilePeriod > 0 {
+		continentRow = nil
+		period := float64(params.TilePeriod)
+		noise = func(x, y, freq float64) float64 {
+			return p.Noise2DPeriodic(x, y, freq, period)
+		}
+		continentNoise = noise
+		flow = func(x, y, freq float64) (float64, float64) {
+			return p.NoiseFlowPeriodic(x, y, freq, period)
+		}
+	}
+	if params.Planet {
+		continentRow = nil
+		noise = p.Equirectangular(width, height)
+		continentNoise = noise
+		// the second flow channel comes from its own noise so the two stay uncorrelated
+		flowY := perlin.NewPerlin(params.Seed+1, perlin.WithGradients(params.Gradients)).Equirectangular(width, height)
+		flow = func(x, y, freq float64) (float64, float64) {
+			return noise(x, y, freq), flowY(x, y, freq)
+		}
+	}
+	octaves, continentOctaves := params.Octaves, params.ContinentOctaves
+	if params.AdaptiveOctaves {
+		octaves = perlin.EffectiveOctaves(octaves, params.Persistence, octaveThreshold)
+		continentOctaves = perlin.EffectiveOctaves(continentOctaves, 0.5, octaveThreshold)
+	}
+	var terrain modules.Module
+	if params.Recipe != nil {
+		g, err := params.Recipe.Build(recipeEnv(params))
+		if err != nil {
+			return nil, fmt.Errorf("terrain recipe: %w", err)
+		}
+		terrain = modules.Clamp{Source: g, Min: 0, Max: 1}
+	} else {
+		// the continent mask is never warped, so it can be sampled once per pixel and reused
+		mask, err := cache.continentMask(ctx, newContinentKey(params, continentOctaves), continentGraph(params, continentNoise, continentRow, continentOctaves), events, o)
+		if err != nil {
+			return nil, err
+		}
+		terrain = terrainGraph(params, noise, gridLookup{grid: mask}, flow, octaves)
+	}
+
+	// each pass fills in between the samples of the one before, so the
+	// coarse ones add no work; the rows of a pass are shared among the CPUs
+	done := events.Track(StageNoise)
+	defer done()
+	heights := make([]float64, width*height)
+	// every pixel is sampled once over all the passes
+	prog := newProgress(events, StageNoise, len(heights))
+	prev := 0
+	for _, step := range passSteps {
+		err := parallelRows(ctx, (height+step-1)/step, o.workers, func(row int) {
+			y := row * step
+			n := 0
+			for x := 0; x < width; x += step {
+				if prev > 0 && x%prev == 0 && y%prev == 0 {
+					continue
+				}
+				heights[y*width+x] = terrain.Sample(float64(x), float64(y))
+				n++
+			}
+			prog.add(n)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if step > 1 && events != nil {
+			events.LayerReady(LayerPreview, newPreview(params, heights, step, reshape))
+		}
+		prev = step
+	}
+	return heights, nil
+}
+
+// ElevationAt returns the normalized height of cell (x, y).
+func (w *World) ElevationAt(x, y int) float64 {
+	if w.quantized != nil {
+		return w.quantized.At(x, y)
+	}
+	return w.Elevation.At(x, y)
+}
+
+// Heights returns the elevation of w as a float64 field: Elevation itself, or
+// for a world generated WithQuantized a new field expanded from its values.
+func (w *World) Heights() *heightfield.Field {
+	if w.quantized != nil {
+		return w.quantized.Elevation()
+	}
+	return w.Elevation
+}
+
+// LandCells returns the number of cells at or above sea level.
+func (w *World) LandCells() int {
+	n := 0
+	for y := 0; y < w.Params.Height; y++ {
+		for x := 0; x < w.Params.Width; x++ {
+			if w.ElevationAt(x, y) >= w.Params.SeaLevel {
+				n++
+			}
+		}
+	}
+	return n
+}
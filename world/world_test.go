@@ -0,0 +1,98 @@
+package world
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestAdaptiveOctavesInvisible generates worlds with and without adaptive
+// octaves. No elevation may move by half an 8-bit step or more, so each cell
+// keeps its 8-bit value or, right at a rounding boundary, a neighbor of it.
+func TestAdaptiveOctavesInvisible(t *testing.T) {
+	for _, octaves := range []int{8, 12, 16} {
+		for _, persistence := range []float64{0.3, 0.5, 0.7} {
+			params := DefaultParams(128, 128)
+			params.Octaves = octaves
+			params.Persistence = persistence
+			params.AdaptiveOctaves = false
+			full, err := Generate(params, nil)
+			if full == nil {
+				t.Fatal(err)
+			}
+			params.AdaptiveOctaves = true
+			adaptive, _ := Generate(params, nil)
+
+			for i, v := range full.Elevation.Data {
+				if d := math.Abs(v - adaptive.Elevation.Data[i]); d >= 0.5/255 {
+					t.Fatalf("%d octaves at persistence %v: cell %d moved by %v", octaves, persistence, i, d)
+				}
+			}
+		}
+	}
+}
+
+// TestQuantized checks that the Heights of a world generated WithQuantized
+// stay within 1/65535 of the height range of the float64 world, and that a
+// Cache, cold or warm, gives exactly what an uncached quantized run gives.
+func TestQuantized(t *testing.T) {
+	for _, smoothing := range []int{0, 2} {
+		params := DefaultParams(128, 128)
+		params.CoastSmoothing = smoothing
+		full, err := Generate(params, nil)
+		if full == nil {
+			t.Fatal(err)
+		}
+		quantized, _ := Generate(params, nil, WithQuantized())
+		if quantized.Elevation != nil {
+			t.Fatal("quantized world keeps a float64 Elevation")
+		}
+		want, got := full.Heights(), quantized.Heights()
+		step := (slices.Max(want.Data) - slices.Min(want.Data)) / math.MaxUint16
+		for i, v := range want.Data {
+			if d := math.Abs(v - got.Data[i]); d > step {
+				t.Fatalf("coast smoothing %d: cell %d moved by %v, step %v", smoothing, i, d, step)
+			}
+		}
+
+		var cache Cache
+		for _, run := range []string{"cold", "warm"} {
+			cached, _ := GenerateCached(context.Background(), params, nil, &cache, WithQuantized())
+			if !reflect.DeepEqual(cached.Heights(), got) || !reflect.DeepEqual(cached.POIs, quantized.POIs) {
+				t.Fatalf("coast smoothing %d: %s cache changed the quantized world", smoothing, run)
+			}
+		}
+	}
+}
+
+// TestPOIDesirability makes the left half of the map undesirable: only the
+// first POI, which is placed anywhere on land, may stand there.
+func TestPOIDesirability(t *testing.T) {
+	params := DefaultParams(128, 128)
+	params.MinDistance = 6
+	w, err := Generate(params, nil, WithPOIDesirability(func(w *World) []float64 {
+		if len(w.POIs) != 0 {
+			t.Error("desirability sees POIs already placed")
+		}
+		d := make([]float64, w.Params.Width*w.Params.Height)
+		for i := range d {
+			if i%w.Params.Width >= w.Params.Width/2 {
+				d[i] = 1
+			}
+		}
+		return d
+	}))
+	if w == nil {
+		t.Fatal(err)
+	}
+	if len(w.POIs) < 2 {
+		t.Fatalf("only %d POIs placed", len(w.POIs))
+	}
+	for _, p := range w.POIs[1:] {
+		if p.X < params.Width/2 {
+			t.Fatalf("POI %v placed on an undesirable cell", p)
+		}
+	}
+}